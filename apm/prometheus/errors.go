@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"context"
+
+	"github.com/kumarabd/gokit/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrorsTotal counts the errors reported through errors.Report
+var ErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gokit",
+	Name:      "errors_total",
+	Help:      "Number of errors reported, by code, severity and component.",
+}, []string{"code", "severity", "component"})
+
+func init() {
+	prometheus.MustRegister(ErrorsTotal)
+	errors.RegisterEventHook(errors.ReportedEvent, errors.NoneSeverity, countReported)
+}
+
+// countReported counts the reported errors, the members of a Multi one by one
+func countReported(_ context.Context, _ errors.HookEvent, err error) {
+	var multi *errors.Multi
+	if errors.As(err, &multi) {
+		for _, member := range multi.Errors() {
+			countError(member)
+		}
+		return
+	}
+	countError(err)
+}
+
+// countError keeps the label cardinality bounded by the catalog: unregistered
+// codes and unknown severities share a single label value
+func countError(err error) {
+	code, component := errors.UnregisteredCode, errors.UnknownComponent
+	if def, ok := errors.Lookup(errors.GetCode(err)); ok {
+		code, component = def.Code, errors.Component(def.Code)
+	}
+	severity := errors.GetSeverity(err)
+	if severity.Rank() == 0 {
+		severity = errors.NoneSeverity
+	}
+	ErrorsTotal.WithLabelValues(code, string(severity), component).Inc()
+}
@@ -0,0 +1,36 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/kumarabd/gokit/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var errTestReported = errors.Register("billing.charge_failed", errors.Alert, "Charge failed", "The payment provider declined the charge.")
+
+func TestReportCounts(t *testing.T) {
+	counter := func(code string, severity errors.Severity, component string) float64 {
+		return testutil.ToFloat64(ErrorsTotal.WithLabelValues(code, string(severity), component))
+	}
+	registered := counter("billing.charge_failed", errors.Alert, "billing")
+	unregistered := counter(errors.UnregisteredCode, errors.Warn, errors.UnknownComponent)
+
+	errors.Report(context.Background(), fmt.Errorf("checkout: %w", errTestReported.New()))
+	errors.Report(context.Background(), errors.Combine(errTestReported.New(), errors.New("adhoc.code", errors.Warn, "Not in the catalog")))
+	errors.Report(context.Background(), io.EOF)
+	errors.Report(context.Background(), nil)
+
+	if got := counter("billing.charge_failed", errors.Alert, "billing") - registered; got != 2 {
+		t.Errorf("registered code counted %v times, want 2", got)
+	}
+	if got := counter(errors.UnregisteredCode, errors.Warn, errors.UnknownComponent) - unregistered; got != 1 {
+		t.Errorf("unregistered code counted %v times, want 1", got)
+	}
+	if got := counter(errors.UnregisteredCode, errors.NoneSeverity, errors.UnknownComponent); got < 1 {
+		t.Errorf("foreign errors counted %v times", got)
+	}
+}
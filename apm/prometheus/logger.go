@@ -0,0 +1,25 @@
+package prometheus
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	// LogsDropped counts log entries discarded by the logger before reaching a sink
+	LogsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gokit",
+		Subsystem: "logger",
+		Name:      "dropped_total",
+		Help:      "Number of log entries dropped by the logger.",
+	}, []string{"app", "level", "reason"})
+
+	// LogQueueDepth reports the number of entries waiting in asynchronous log writers
+	LogQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "gokit",
+		Subsystem: "logger",
+		Name:      "queue_depth",
+		Help:      "Number of log entries buffered by asynchronous writers.",
+	}, []string{"app"})
+)
+
+func init() {
+	prometheus.MustRegister(LogsDropped, LogQueueDepth)
+}
@@ -1,8 +1,49 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
+// NoExpiration is the TTL of keys that do not expire
+const NoExpiration time.Duration = -1
+
+// Handler is implemented by the cache backends. Operations on missing or
+// expired keys return ErrKeyNotExist, except Delete and Exists.
 type Handler interface {
-	Get(key string) (interface{}, error)
-	Set(key string, value interface{}, exp ...time.Duration) error
+	// Get returns the value of a key
+	Get(ctx context.Context, key string) (interface{}, error)
+	// Set stores the value of a key. Without exp the key does not expire, a
+	// zero exp applies the default expiration of the backend and a negative
+	// one, such as NoExpiration, also keeps the key until it is removed.
+	Set(ctx context.Context, key string, value interface{}, exp ...time.Duration) error
+	// Delete removes a key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// Exists returns whether a key is set
+	Exists(ctx context.Context, key string) (bool, error)
+	// TTL returns the remaining time to live of a key, NoExpiration for
+	// keys without expiration
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Expire sets the expiration of a key, a non-positive exp removes it
+	Expire(ctx context.Context, key string, exp time.Duration) error
+	// Keys returns the keys matching a glob pattern in no particular order,
+	// see Match
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	// Clear removes every key
+	Clear(ctx context.Context) error
+}
+
+// Stats holds the counters of a backend
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// StatsReporter is implemented by the backends keeping statistics
+type StatsReporter interface {
+	Stats() Stats
 }
@@ -0,0 +1,249 @@
+// Package cachetest is a conformance suite for cache.Handler implementations
+package cachetest
+
+import (
+	"context"
+	stderrors "errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/cache"
+	"github.com/kumarabd/gokit/errors"
+)
+
+// Options configures the suite for a backend
+type Options struct {
+	// TTL is the expiration used by the suite, 100ms when zero
+	TTL time.Duration
+	// Slack is the margin given to the backend to honour TTL, 50ms when zero
+	Slack time.Duration
+	// Expiration is the default expiration of the handlers returned by
+	// newHandler, none when zero
+	Expiration time.Duration
+	// Wait lets the time pass for the expiration tests, time.Sleep when nil.
+	// Backends with a simulated clock advance their clock instead of sleeping.
+	Wait func(d time.Duration)
+}
+
+// Run runs the conformance suite, newHandler returns an empty handler for
+// each test
+func Run(t *testing.T, opts Options, newHandler func(t *testing.T) cache.Handler) {
+	if opts.TTL <= 0 {
+		opts.TTL = 100 * time.Millisecond
+	}
+	if opts.Slack <= 0 {
+		opts.Slack = 50 * time.Millisecond
+	}
+	if opts.Wait == nil {
+		opts.Wait = time.Sleep
+	}
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, h cache.Handler, opts Options)
+	}{
+		{"GetSet", testGetSet},
+		{"Delete", testDelete},
+		{"Exists", testExists},
+		{"Expiration", testExpiration},
+		{"TTL", testTTL},
+		{"SetExpiration", testSetExpiration},
+		{"Expire", testExpire},
+		{"Keys", testKeys},
+		{"Clear", testClear},
+		{"Context", testContext},
+	} {
+		t.Run(tc.name, func(t *testing.T) { tc.test(t, newHandler(t), opts) })
+	}
+}
+
+func testGetSet(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	if _, err := h.Get(ctx, "missing"); !stderrors.Is(err, cache.ErrKeyNotExist) {
+		t.Errorf("Get() of a missing key error = %v, want ErrKeyNotExist", err)
+	}
+	mustSet(t, h, "user:1", "alice")
+	mustSet(t, h, "user:1", "bob")
+	if value, err := h.Get(ctx, "user:1"); err != nil || value != "bob" {
+		t.Errorf("Get() = %v, %v, want the last value", value, err)
+	}
+}
+
+func testDelete(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "user:1", "alice")
+	if err := h.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := h.Get(ctx, "user:1"); !stderrors.Is(err, cache.ErrKeyNotExist) {
+		t.Errorf("Get() of a deleted key error = %v", err)
+	}
+	if err := h.Delete(ctx, "user:1"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v", err)
+	}
+}
+
+func testExists(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "user:1", "alice")
+	for key, want := range map[string]bool{"user:1": true, "user:2": false} {
+		if ok, err := h.Exists(ctx, key); err != nil || ok != want {
+			t.Errorf("Exists(%q) = %v, %v, want %v", key, ok, err, want)
+		}
+	}
+}
+
+func testExpiration(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "session", "token", opts.TTL)
+	opts.Wait(opts.TTL + opts.Slack)
+	if _, err := h.Get(ctx, "session"); !stderrors.Is(err, cache.ErrKeyNotExist) {
+		t.Errorf("Get() of an expired key error = %v", err)
+	}
+	if ok, err := h.Exists(ctx, "session"); err != nil || ok {
+		t.Errorf("Exists() of an expired key = %v, %v", ok, err)
+	}
+}
+
+func testTTL(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "session", "token", opts.TTL)
+	mustSet(t, h, "config", "value")
+	if ttl, err := h.TTL(ctx, "session"); err != nil || ttl <= 0 || ttl > opts.TTL {
+		t.Errorf("TTL() = %v, %v, want in (0, %v]", ttl, err, opts.TTL)
+	}
+	if ttl, err := h.TTL(ctx, "config"); err != nil || ttl != cache.NoExpiration {
+		t.Errorf("TTL() of a persistent key = %v, %v, want NoExpiration", ttl, err)
+	}
+	if _, err := h.TTL(ctx, "missing"); !stderrors.Is(err, cache.ErrKeyNotExist) {
+		t.Errorf("TTL() of a missing key error = %v", err)
+	}
+}
+
+func testSetExpiration(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "omitted", "value")
+	mustSet(t, h, "never", "value", cache.NoExpiration)
+	mustSet(t, h, "default", "value", 0)
+	for _, key := range []string{"omitted", "never"} {
+		if ttl, err := h.TTL(ctx, key); err != nil || ttl != cache.NoExpiration {
+			t.Errorf("TTL() of %q = %v, %v, want NoExpiration", key, ttl, err)
+		}
+	}
+	ttl, err := h.TTL(ctx, "default")
+	switch {
+	case err != nil:
+		t.Errorf("TTL() of a key set with a zero expiration error = %v", err)
+	case opts.Expiration == 0 && ttl != cache.NoExpiration:
+		t.Errorf("TTL() of a key set with a zero expiration = %v, want NoExpiration without default", ttl)
+	case opts.Expiration > 0 && (ttl <= 0 || ttl > opts.Expiration):
+		t.Errorf("TTL() of a key set with a zero expiration = %v, want the default in (0, %v]", ttl, opts.Expiration)
+	}
+}
+
+func testExpire(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "session", "token")
+	if err := h.Expire(ctx, "session", time.Hour); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+	if ttl, err := h.TTL(ctx, "session"); err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Errorf("TTL() after Expire() = %v, %v", ttl, err)
+	}
+	if value, err := h.Get(ctx, "session"); err != nil || value != "token" {
+		t.Errorf("Get() after Expire() = %v, %v, want the value kept", value, err)
+	}
+	if err := h.Expire(ctx, "session", 0); err != nil {
+		t.Fatalf("Expire(0) error = %v", err)
+	}
+	if ttl, err := h.TTL(ctx, "session"); err != nil || ttl != cache.NoExpiration {
+		t.Errorf("TTL() after Expire(0) = %v, %v, want NoExpiration", ttl, err)
+	}
+	if err := h.Expire(ctx, "missing", time.Hour); !stderrors.Is(err, cache.ErrKeyNotExist) {
+		t.Errorf("Expire() of a missing key error = %v", err)
+	}
+
+	if err := h.Expire(ctx, "session", opts.TTL); err != nil {
+		t.Fatalf("Expire() error = %v", err)
+	}
+	opts.Wait(opts.TTL + opts.Slack)
+	if ok, _ := h.Exists(ctx, "session"); ok {
+		t.Error("key still exists after its expiration")
+	}
+}
+
+func testKeys(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	for _, key := range []string{"user:1", "user:2", "user:10", "order:1", "a*b"} {
+		mustSet(t, h, key, "value")
+	}
+	mustSet(t, h, "user:3", "value", opts.TTL)
+	opts.Wait(opts.TTL + opts.Slack)
+
+	for pattern, want := range map[string][]string{
+		"*":          {"a*b", "order:1", "user:1", "user:10", "user:2"},
+		"user:*":     {"user:1", "user:10", "user:2"},
+		"user:?":     {"user:1", "user:2"},
+		"[ou]*:1":    {"order:1", "user:1"},
+		"user:[^1]":  {"user:2"},
+		"user:[!1]":  {"user:1"},
+		`a\*b`:       {"a*b"},
+		"session:*":  {},
+		"user:[0-1]": {"user:1"},
+	} {
+		keys, err := h.Keys(ctx, pattern)
+		if err != nil {
+			t.Errorf("Keys(%q) error = %v", pattern, err)
+			continue
+		}
+		sort.Strings(keys)
+		if !equal(keys, want) {
+			t.Errorf("Keys(%q) = %v, want %v", pattern, keys, want)
+		}
+	}
+	if _, err := h.Keys(ctx, "user:[1"); !errors.HasCode(err, cache.ErrInvalidPattern.Code) {
+		t.Errorf("Keys() of an invalid pattern error = %v", err)
+	}
+}
+
+func testClear(t *testing.T, h cache.Handler, opts Options) {
+	ctx := context.Background()
+	mustSet(t, h, "user:1", "alice")
+	mustSet(t, h, "user:2", "bob", time.Hour)
+	if err := h.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if keys, err := h.Keys(ctx, "*"); err != nil || len(keys) != 0 {
+		t.Errorf("Keys() after Clear() = %v, %v", keys, err)
+	}
+}
+
+func testContext(t *testing.T, h cache.Handler, opts Options) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := h.Set(ctx, "user:1", "alice"); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("Set() with a canceled context error = %v", err)
+	}
+	if _, err := h.Get(ctx, "user:1"); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("Get() with a canceled context error = %v", err)
+	}
+}
+
+func mustSet(t *testing.T, h cache.Handler, key string, value interface{}, exp ...time.Duration) {
+	t.Helper()
+	if err := h.Set(context.Background(), key, value, exp...); err != nil {
+		t.Fatalf("Set(%q) error = %v", key, err)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
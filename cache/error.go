@@ -0,0 +1,12 @@
+package cache
+
+import "github.com/kumarabd/gokit/errors"
+
+var (
+	ErrKeyNotExist = errors.Register("cache.key_not_exist", errors.Alert, "Key does not exist",
+		"The requested key is not in the cache, either it was never set or it has expired.").New()
+	ErrInvalidPattern = errors.Register("cache.invalid_pattern", errors.Warn, "Invalid key pattern %q",
+		"The pattern passed to Keys is malformed, check for unclosed character classes and trailing escapes.")
+	ErrLoadPanicked = errors.Register("cache.load_panicked", errors.Critical, "Loading key %q panicked",
+		"The LoadFunc passed to GetOrLoad panicked, the wrapped error holds the panic value and the stack points to the load.")
+)
@@ -0,0 +1,293 @@
+package inmem
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kumarabd/gokit/cache"
+)
+
+// expireSamples is the number of entries checked for expiration on writes
+const expireSamples = 4
+
+// entry is a key of the bounded cache
+type entry struct {
+	key        string
+	value      interface{}
+	expiration time.Time
+	size       int64
+
+	// Bookkeeping of the eviction policies
+	elem    *list.Element
+	segment int
+	freq    uint64
+	seq     uint64
+	index   int
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiration.IsZero() && now.After(e.expiration)
+}
+
+// bounded is an in-memory cache holding at most a number of entries or an
+// approximate number of bytes
+type bounded struct {
+	mu         sync.Mutex
+	entries    map[string]*entry
+	policy     policy
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	expiration time.Duration
+	sizer      func(key string, value interface{}) int64
+	stats      cache.Stats
+}
+
+func newBounded(opts Options) (*bounded, error) {
+	h := &bounded{
+		entries:    make(map[string]*entry),
+		maxEntries: opts.MaxEntries,
+		maxBytes:   opts.MaxBytes,
+		expiration: opts.Expiration,
+		sizer:      opts.Sizer,
+	}
+	if h.sizer == nil {
+		h.sizer = ApproximateSize
+	}
+
+	switch opts.Eviction {
+	case LRU, "":
+		h.policy = newLRU()
+	case LFU:
+		h.policy = newLFU()
+	case TinyLFU:
+		capacity := opts.MaxEntries
+		if capacity <= 0 {
+			capacity = int(opts.MaxBytes / 64)
+		}
+		h.policy = newTinyLFU(capacity)
+	default:
+		return nil, ErrInvalidEviction.New(opts.Eviction)
+	}
+	return h, nil
+}
+
+func (h *bounded) Get(ctx context.Context, key string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.lookup(key, time.Now())
+	if !ok {
+		h.stats.Misses++
+		return nil, ErrKeyNotExist
+	}
+	h.stats.Hits++
+	h.policy.access(e)
+	return e.value, nil
+}
+
+func (h *bounded) Set(ctx context.Context, key string, value interface{}, exp ...time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	var expiration time.Time
+	if len(exp) > 0 {
+		d := exp[0]
+		if d == 0 {
+			d = h.expiration
+		}
+		if d > 0 {
+			expiration = now.Add(d)
+		}
+	}
+	size := h.sizer(key, value)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expireSample(now)
+	e, ok := h.entries[key]
+	if h.maxBytes > 0 && size > h.maxBytes {
+		// Storing the entry would evict every other one
+		if ok {
+			h.remove(e)
+		}
+		h.stats.Evictions++
+		return nil
+	}
+	if ok {
+		h.bytes += size - e.size
+		e.value, e.expiration, e.size = value, expiration, size
+		h.policy.access(e)
+		h.evict(now, 0, 0)
+		return nil
+	}
+
+	// Make room before adding, so the new entry is not the victim of
+	// frequency based policies
+	h.evict(now, 1, size)
+	e = &entry{key: key, value: value, expiration: expiration, size: size}
+	h.entries[key] = e
+	h.bytes += size
+	h.policy.add(e)
+	return nil
+}
+
+func (h *bounded) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if e, ok := h.entries[key]; ok {
+		h.remove(e)
+	}
+	return nil
+}
+
+func (h *bounded) Exists(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.lookup(key, time.Now())
+	return ok, nil
+}
+
+func (h *bounded) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.lookup(key, now)
+	if !ok {
+		return 0, ErrKeyNotExist
+	}
+	if e.expiration.IsZero() {
+		return cache.NoExpiration, nil
+	}
+	return e.expiration.Sub(now), nil
+}
+
+func (h *bounded) Expire(ctx context.Context, key string, exp time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.lookup(key, now)
+	if !ok {
+		return ErrKeyNotExist
+	}
+	e.expiration = time.Time{}
+	if exp > 0 {
+		e.expiration = now.Add(exp)
+	}
+	return nil
+}
+
+func (h *bounded) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := cache.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := []string{}
+	for key, e := range h.entries {
+		if e.expired(now) {
+			continue
+		}
+		if ok, _ := cache.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (h *bounded) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = make(map[string]*entry)
+	h.bytes = 0
+	h.policy.reset()
+	return nil
+}
+
+// Stats returns the counters of the cache
+func (h *bounded) Stats() cache.Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats := h.stats
+	stats.Entries = len(h.entries)
+	stats.Bytes = h.bytes
+	return stats
+}
+
+// lookup returns the entry of a key, removing it when expired
+func (h *bounded) lookup(key string, now time.Time) (*entry, bool) {
+	e, ok := h.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if e.expired(now) {
+		h.remove(e)
+		h.stats.Expirations++
+		return nil, false
+	}
+	return e, true
+}
+
+// expireSample removes the expired entries among a few of the map, so expired
+// entries that are never read again do not stay until evicted
+func (h *bounded) expireSample(now time.Time) {
+	n := 0
+	for _, e := range h.entries {
+		if n++; n > expireSamples {
+			return
+		}
+		if e.expired(now) {
+			h.remove(e)
+			h.stats.Expirations++
+		}
+	}
+}
+
+// evict removes the victims of the policy until an entry of the given size
+// fits within the bounds
+func (h *bounded) evict(now time.Time, entries int, size int64) {
+	for (h.maxEntries > 0 && len(h.entries)+entries > h.maxEntries) || (h.maxBytes > 0 && h.bytes+size > h.maxBytes) {
+		e := h.policy.victim()
+		if e == nil {
+			return
+		}
+		h.remove(e)
+		if e.expired(now) {
+			h.stats.Expirations++
+		} else {
+			h.stats.Evictions++
+		}
+	}
+}
+
+func (h *bounded) remove(e *entry) {
+	delete(h.entries, e.key)
+	h.bytes -= e.size
+	h.policy.remove(e)
+}
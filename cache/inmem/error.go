@@ -1,7 +1,14 @@
 package inmem
 
-import "github.com/kumarabd/gokit/errors"
+import (
+	"github.com/kumarabd/gokit/cache"
+	"github.com/kumarabd/gokit/errors"
+)
 
 var (
-	ErrKeyNotExist = errors.New("", errors.Alert, "Key does not exist")
+	// ErrKeyNotExist is cache.ErrKeyNotExist, kept for existing callers
+	ErrKeyNotExist = cache.ErrKeyNotExist
+
+	ErrInvalidEviction = errors.Register("cache.invalid_eviction", errors.Warn, "Invalid eviction policy %q",
+		"The eviction policy of the in-memory cache must be lru, lfu or tinylfu.")
 )
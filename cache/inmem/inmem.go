@@ -1,6 +1,9 @@
 package inmem
 
 import (
+	"context"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/kumarabd/gokit/cache"
@@ -9,21 +12,45 @@ import (
 
 type inmem struct {
 	handler *gocache.Cache
+	// mu serialises the writes so Expire does not overwrite a concurrent Set
+	mu sync.Mutex
 }
 
 type Options struct {
-	Expiration      time.Duration
-	CleanupInterval time.Duration
+	Expiration      time.Duration `json:"expiration,omitempty" yaml:"expiration,omitempty"`
+	CleanupInterval time.Duration `json:"cleanup_interval,omitempty" yaml:"cleanup_interval,omitempty"`
+	// MaxEntries bounds the number of entries, unbounded when zero
+	MaxEntries int `json:"max_entries,omitempty" yaml:"max_entries,omitempty"`
+	// MaxBytes bounds the approximate size of the entries, unbounded when zero
+	MaxBytes int64 `json:"max_bytes,omitempty" yaml:"max_bytes,omitempty"`
+	// Eviction selects the entries evicted from a bounded cache, LRU when empty
+	Eviction Eviction `json:"eviction,omitempty" yaml:"eviction,omitempty"`
+	// Sizer returns the size of an entry for MaxBytes, ApproximateSize when nil
+	Sizer func(key string, value interface{}) int64 `json:"-" yaml:"-"`
 }
 
+// New returns an in-memory cache. With MaxEntries or MaxBytes set, the cache
+// is bounded, evicts entries with the Eviction policy, implements
+// cache.StatsReporter and removes expired entries lazily instead of with a
+// cleanup goroutine.
 func New(opts Options) (cache.Handler, error) {
+	if opts.MaxEntries > 0 || opts.MaxBytes > 0 {
+		h, err := newBounded(opts)
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	}
 	h := gocache.New(opts.Expiration, opts.CleanupInterval)
 	return &inmem{
 		handler: h,
 	}, nil
 }
 
-func (h *inmem) Get(key string) (interface{}, error) {
+func (h *inmem) Get(ctx context.Context, key string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	res, ok := h.handler.Get(key)
 	if !ok {
 		return res, ErrKeyNotExist
@@ -31,7 +58,12 @@ func (h *inmem) Get(key string) (interface{}, error) {
 	return res, nil
 }
 
-func (h *inmem) Set(key string, value interface{}, exp ...time.Duration) error {
+func (h *inmem) Set(ctx context.Context, key string, value interface{}, exp ...time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if len(exp) == 0 {
 		h.handler.Set(key, value, gocache.NoExpiration)
 	} else {
@@ -39,3 +71,80 @@ func (h *inmem) Set(key string, value interface{}, exp ...time.Duration) error {
 	}
 	return nil
 }
+
+func (h *inmem) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handler.Delete(key)
+	return nil
+}
+
+func (h *inmem) Exists(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	_, ok := h.handler.Get(key)
+	return ok, nil
+}
+
+func (h *inmem) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	_, expiration, ok := h.handler.GetWithExpiration(key)
+	if !ok {
+		return 0, ErrKeyNotExist
+	}
+	if expiration.IsZero() {
+		return cache.NoExpiration, nil
+	}
+	return time.Until(expiration), nil
+}
+
+func (h *inmem) Expire(ctx context.Context, key string, exp time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if exp <= 0 {
+		exp = gocache.NoExpiration
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value, ok := h.handler.Get(key)
+	if !ok {
+		return ErrKeyNotExist
+	}
+	h.handler.Set(key, value, exp)
+	return nil
+}
+
+func (h *inmem) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Validate the pattern even when the cache is empty
+	if _, err := cache.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for key := range h.handler.Items() {
+		if ok, _ := cache.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (h *inmem) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handler.Flush()
+	return nil
+}
@@ -0,0 +1,152 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/cache"
+	"github.com/kumarabd/gokit/cache/cachetest"
+	"github.com/kumarabd/gokit/errors"
+)
+
+func TestConformance(t *testing.T) {
+	for name, opts := range map[string]Options{
+		"unbounded":   {CleanupInterval: time.Minute},
+		"lru":         {MaxEntries: 100, Eviction: LRU},
+		"lfu":         {MaxEntries: 100, Eviction: LFU},
+		"tinylfu":     {MaxEntries: 100, Eviction: TinyLFU},
+		"bytes":       {MaxBytes: 1 << 20},
+		"default":     {Expiration: time.Hour, CleanupInterval: time.Minute},
+		"lru+default": {MaxEntries: 100, Expiration: time.Hour},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cachetest.Run(t, cachetest.Options{Expiration: opts.Expiration}, func(t *testing.T) cache.Handler {
+				h, err := New(opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return h
+			})
+		})
+	}
+}
+
+func newBoundedHandler(t *testing.T, opts Options) cache.Handler {
+	h, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestEviction(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		eviction Eviction
+		kept     string
+		evicted  string
+	}{
+		// "a" is read the most but "b" the most recently
+		{LRU, "b", "a"},
+		{LFU, "a", "b"},
+	} {
+		h := newBoundedHandler(t, Options{MaxEntries: 2, Eviction: tc.eviction})
+		h.Set(ctx, "a", 1)
+		h.Set(ctx, "b", 2)
+		for i := 0; i < 3; i++ {
+			h.Get(ctx, "a")
+		}
+		h.Get(ctx, "b")
+		h.Set(ctx, "c", 3)
+
+		if ok, _ := h.Exists(ctx, tc.kept); !ok {
+			t.Errorf("%s evicted %q", tc.eviction, tc.kept)
+		}
+		if ok, _ := h.Exists(ctx, tc.evicted); ok {
+			t.Errorf("%s kept %q", tc.eviction, tc.evicted)
+		}
+		if stats := h.(cache.StatsReporter).Stats(); stats.Evictions != 1 || stats.Entries != 2 || stats.Hits != 4 {
+			t.Errorf("%s stats = %+v", tc.eviction, stats)
+		}
+	}
+}
+
+func TestTinyLFUKeepsFrequentKeys(t *testing.T) {
+	ctx := context.Background()
+	h := newBoundedHandler(t, Options{MaxEntries: 100, Eviction: TinyLFU})
+	for i := 0; i < 100; i++ {
+		h.Set(ctx, fmt.Sprintf("hot:%d", i), i)
+	}
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 100; i++ {
+			h.Get(ctx, fmt.Sprintf("hot:%d", i))
+		}
+	}
+	// A scan of keys read once must not flush the frequently read ones
+	for i := 0; i < 1000; i++ {
+		h.Set(ctx, fmt.Sprintf("scan:%d", i), i)
+	}
+
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if ok, _ := h.Exists(ctx, fmt.Sprintf("hot:%d", i)); ok {
+			kept++
+		}
+	}
+	if kept < 90 {
+		t.Errorf("%d frequently read keys kept, want at least 90", kept)
+	}
+	if stats := h.(cache.StatsReporter).Stats(); stats.Entries != 100 || stats.Evictions != 1000 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	size := func(key string, value interface{}) int64 { return int64(len(value.(string))) }
+	h := newBoundedHandler(t, Options{MaxBytes: 10, Sizer: size})
+	h.Set(ctx, "a", "1234")
+	h.Set(ctx, "b", "1234")
+	h.Set(ctx, "c", "1234")
+	if ok, _ := h.Exists(ctx, "a"); ok {
+		t.Error("oldest entry kept over the byte limit")
+	}
+	h.Set(ctx, "d", "12345678901")
+	if stats := h.(cache.StatsReporter).Stats(); stats.Entries != 2 || stats.Bytes != 8 || stats.Evictions != 2 {
+		t.Errorf("stats after an entry larger than the limit = %+v", stats)
+	}
+}
+
+func TestExpirationStats(t *testing.T) {
+	ctx := context.Background()
+	h := newBoundedHandler(t, Options{MaxEntries: 10})
+	h.Set(ctx, "session", "token", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	h.Get(ctx, "session")
+	if stats := h.(cache.StatsReporter).Stats(); stats.Expirations != 1 || stats.Misses != 1 || stats.Entries != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestInvalidEviction(t *testing.T) {
+	if _, err := New(Options{MaxEntries: 1, Eviction: "random"}); !errors.HasCode(err, ErrInvalidEviction.Code) {
+		t.Errorf("New() error = %v", err)
+	}
+}
+
+func TestApproximateSize(t *testing.T) {
+	type user struct {
+		Name  string
+		Roles []string
+	}
+	small := ApproximateSize("k", user{Name: "a"})
+	large := ApproximateSize("k", &user{Name: "a", Roles: []string{"admin", "billing", "support"}})
+	if small <= entryOverhead || large <= small {
+		t.Errorf("ApproximateSize() = %d and %d", small, large)
+	}
+	if got := ApproximateSize("key", make([]byte, 1<<20)); got < 1<<20 {
+		t.Errorf("ApproximateSize() of 1MiB = %d", got)
+	}
+}
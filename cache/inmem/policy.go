@@ -0,0 +1,284 @@
+package inmem
+
+import (
+	"container/heap"
+	"container/list"
+	"hash/maphash"
+)
+
+// Eviction selects the entries removed from a bounded cache
+type Eviction string
+
+const (
+	// LRU evicts the least recently used entries
+	LRU Eviction = "lru"
+	// LFU evicts the least frequently used entries
+	LFU Eviction = "lfu"
+	// TinyLFU is W-TinyLFU: new entries go through a small LRU window and
+	// only replace entries of the main LRU when they are used more often,
+	// according to a decaying frequency sketch
+	TinyLFU Eviction = "tinylfu"
+)
+
+// policy orders the entries of a bounded cache, calls are serialised by the
+// cache
+type policy interface {
+	add(e *entry)
+	access(e *entry)
+	remove(e *entry)
+	// victim returns the next entry to evict, nil when empty
+	victim() *entry
+	reset()
+}
+
+type lru struct {
+	entries *list.List
+}
+
+func newLRU() *lru {
+	return &lru{entries: list.New()}
+}
+
+func (p *lru) add(e *entry)    { e.elem = p.entries.PushFront(e) }
+func (p *lru) access(e *entry) { p.entries.MoveToFront(e.elem) }
+func (p *lru) remove(e *entry) { p.entries.Remove(e.elem) }
+func (p *lru) reset()          { p.entries.Init() }
+
+func (p *lru) victim() *entry {
+	if back := p.entries.Back(); back != nil {
+		return back.Value.(*entry)
+	}
+	return nil
+}
+
+// lfu evicts the entry with the lowest use count, the least recently used
+// one among equals
+type lfu struct {
+	entries lfuHeap
+	seq     uint64
+}
+
+func newLFU() *lfu {
+	return &lfu{}
+}
+
+func (p *lfu) add(e *entry) {
+	p.seq++
+	e.freq, e.seq = 1, p.seq
+	heap.Push(&p.entries, e)
+}
+
+func (p *lfu) access(e *entry) {
+	p.seq++
+	e.freq, e.seq = e.freq+1, p.seq
+	heap.Fix(&p.entries, e.index)
+}
+
+func (p *lfu) remove(e *entry) { heap.Remove(&p.entries, e.index) }
+func (p *lfu) reset()          { p.entries = nil }
+
+func (p *lfu) victim() *entry {
+	if len(p.entries) == 0 {
+		return nil
+	}
+	return p.entries[0]
+}
+
+type lfuHeap []*entry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+// Segments of W-TinyLFU
+const (
+	windowSegment = iota
+	probationSegment
+	protectedSegment
+)
+
+// tinyLFU keeps 1% of the entries in an LRU window and the others in a
+// segmented LRU, 80% of which is protected. Entries leaving the window are
+// candidates that replace the probation victim only if the sketch estimates
+// they are used more often.
+type tinyLFU struct {
+	sketch    *sketch
+	segments  [3]*list.List
+	candidate *entry
+	total     int
+}
+
+func newTinyLFU(capacity int) *tinyLFU {
+	return &tinyLFU{
+		sketch:   newSketch(capacity),
+		segments: [3]*list.List{list.New(), list.New(), list.New()},
+	}
+}
+
+func (p *tinyLFU) add(e *entry) {
+	p.sketch.increment(e.key)
+	p.total++
+	p.push(e, windowSegment)
+
+	window := p.segments[windowSegment]
+	if window.Len() > max(1, p.total/100) {
+		candidate := window.Back().Value.(*entry)
+		window.Remove(candidate.elem)
+		p.push(candidate, probationSegment)
+		p.candidate = candidate
+	}
+}
+
+func (p *tinyLFU) access(e *entry) {
+	p.sketch.increment(e.key)
+	switch e.segment {
+	case windowSegment, protectedSegment:
+		p.segments[e.segment].MoveToFront(e.elem)
+	case probationSegment:
+		p.segments[probationSegment].Remove(e.elem)
+		p.push(e, protectedSegment)
+		if e == p.candidate {
+			p.candidate = nil
+		}
+
+		protected := p.segments[protectedSegment]
+		if protected.Len() > max(1, (p.total-p.segments[windowSegment].Len())*8/10) {
+			demoted := protected.Back().Value.(*entry)
+			protected.Remove(demoted.elem)
+			p.push(demoted, probationSegment)
+		}
+	}
+}
+
+func (p *tinyLFU) remove(e *entry) {
+	p.segments[e.segment].Remove(e.elem)
+	p.total--
+	if e == p.candidate {
+		p.candidate = nil
+	}
+}
+
+func (p *tinyLFU) victim() *entry {
+	probation := p.segments[probationSegment]
+	if probation.Len() == 0 {
+		for _, segment := range []int{protectedSegment, windowSegment} {
+			if back := p.segments[segment].Back(); back != nil {
+				return back.Value.(*entry)
+			}
+		}
+		return nil
+	}
+
+	victim := probation.Back().Value.(*entry)
+	candidate := p.candidate
+	p.candidate = nil
+	if candidate == nil || candidate == victim {
+		return victim
+	}
+	if p.sketch.estimate(candidate.key) > p.sketch.estimate(victim.key) {
+		return victim
+	}
+	return candidate
+}
+
+func (p *tinyLFU) reset() {
+	for _, segment := range p.segments {
+		segment.Init()
+	}
+	p.candidate = nil
+	p.total = 0
+	p.sketch.reset()
+}
+
+func (p *tinyLFU) push(e *entry, segment int) {
+	e.segment = segment
+	e.elem = p.segments[segment].PushFront(e)
+}
+
+// sketch is a count-min sketch of counters saturating at 15, with about 8
+// counters per entry and row. The counts are halved every 10 increments per
+// entry of the cache, so the estimates favour recent uses.
+type sketch struct {
+	rows      [4][]uint8
+	mask      uint64
+	seed      maphash.Seed
+	additions int
+	period    int
+}
+
+func newSketch(capacity int) *sketch {
+	capacity = max(capacity, 16)
+	width := 128
+	for width < 8*capacity && width < 1<<24 {
+		width <<= 1
+	}
+	s := &sketch{mask: uint64(width - 1), seed: maphash.MakeSeed(), period: 10 * capacity}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *sketch) increment(key string) {
+	h := maphash.String(s.seed, key)
+	for i := range s.rows {
+		if c := &s.rows[i][s.index(h, i)]; *c < 15 {
+			*c++
+		}
+	}
+	if s.additions++; s.additions >= s.period {
+		for i := range s.rows {
+			for j := range s.rows[i] {
+				s.rows[i][j] >>= 1
+			}
+		}
+		s.additions /= 2
+	}
+}
+
+func (s *sketch) estimate(key string) uint8 {
+	h := maphash.String(s.seed, key)
+	least := uint8(15)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < least {
+			least = c
+		}
+	}
+	return least
+}
+
+func (s *sketch) reset() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.additions = 0
+}
+
+// index derives the counter of a row by double hashing
+func (s *sketch) index(h uint64, row int) uint64 {
+	return (h + uint64(row)*(h>>32|1)) & s.mask
+}
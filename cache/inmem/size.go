@@ -0,0 +1,74 @@
+package inmem
+
+import "reflect"
+
+// entryOverhead approximates the memory used by the bookkeeping of an entry
+const entryOverhead = 128
+
+// ApproximateSize estimates the memory held by an entry: the key, the value
+// and what it references through pointers, slices, maps and interfaces, and
+// a fixed overhead. Memory shared between values is counted once per value.
+func ApproximateSize(key string, value interface{}) int64 {
+	seen := make(map[uintptr]bool)
+	return entryOverhead + int64(len(key)) + sizeOf(reflect.ValueOf(value), seen)
+}
+
+func sizeOf(v reflect.Value, seen map[uintptr]bool) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	size := int64(v.Type().Size())
+	switch v.Kind() {
+	case reflect.String:
+		size += int64(v.Len())
+	case reflect.Ptr:
+		if !v.IsNil() && !seen[v.Pointer()] {
+			seen[v.Pointer()] = true
+			size += sizeOf(v.Elem(), seen)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			size += sizeOf(v.Elem(), seen)
+		}
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			break
+		}
+		seen[v.Pointer()] = true
+		size += int64(v.Cap()-v.Len())*int64(v.Type().Elem().Size()) + elementsSize(v, seen)
+	case reflect.Array:
+		size = elementsSize(v, seen)
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			break
+		}
+		seen[v.Pointer()] = true
+		iter := v.MapRange()
+		for iter.Next() {
+			size += sizeOf(iter.Key(), seen) + sizeOf(iter.Value(), seen)
+		}
+	case reflect.Struct:
+		size = 0
+		for i := 0; i < v.NumField(); i++ {
+			size += sizeOf(v.Field(i), seen)
+		}
+		// Padding between the fields
+		size = max(size, int64(v.Type().Size()))
+	}
+	return size
+}
+
+// elementsSize returns the size of the elements of a slice or an array
+func elementsSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Type().Elem().Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return int64(v.Len()) * int64(v.Type().Elem().Size())
+	}
+	size := int64(0)
+	for i := 0; i < v.Len(); i++ {
+		size += sizeOf(v.Index(i), seen)
+	}
+	return size
+}
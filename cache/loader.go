@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadFunc loads the value of a key missing from the cache. Returning an
+// error matching ErrKeyNotExist marks the key as missing from the source.
+type LoadFunc func(ctx context.Context, key string) (interface{}, error)
+
+// LoaderOptions configures a Loader
+type LoaderOptions struct {
+	// NegativeTTL is how long missing keys reported by a LoadFunc are
+	// remembered, negative results are not cached when zero
+	NegativeTTL time.Duration `json:"negative_ttl,omitempty" yaml:"negative_ttl,omitempty"`
+	// MaxNegative bounds the number of missing keys remembered, 10000 when zero
+	MaxNegative int `json:"max_negative,omitempty" yaml:"max_negative,omitempty"`
+}
+
+// Loader is a Handler that loads missing keys through GetOrLoad, running a
+// single load per key at a time. Negative results are remembered in the
+// process, so they work with every backend. Set, Delete and Clear through the
+// Loader forget them, and keep the loads in flight from caching stale values.
+type Loader struct {
+	Handler
+
+	opts  LoaderOptions
+	group singleflight.Group
+
+	mu       sync.Mutex
+	negative map[string]time.Time
+	loading  map[string]*loadState
+}
+
+// loadState tracks a key being loaded
+type loadState struct {
+	// generation is incremented by Set, Delete and Clear so the load does
+	// not overwrite them, guarded by the mutex of the Loader
+	generation uint64
+	// storing is held while the loaded value is written to the backend, so
+	// only the changes of the same key wait for the write
+	storing sync.Mutex
+}
+
+// NewLoader returns a Loader on top of h
+func NewLoader(h Handler, opts LoaderOptions) *Loader {
+	if opts.MaxNegative <= 0 {
+		opts.MaxNegative = 10000
+	}
+	return &Loader{
+		Handler:  h,
+		opts:     opts,
+		negative: make(map[string]time.Time),
+		loading:  make(map[string]*loadState),
+	}
+}
+
+// GetOrLoad returns the cached value of key, or loads it with load and caches
+// it for ttl, the default expiration of the backend when ttl is not positive.
+// Concurrent calls for the same key share a single load, which is not
+// canceled when one of the callers gives up. A panicking load fails with
+// ErrLoadPanicked. Cache errors other than misses do not fail the call.
+func (l *Loader) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load LoadFunc) (interface{}, error) {
+	value, err := l.Handler.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if l.isNegative(key) {
+		return nil, ErrKeyNotExist
+	}
+
+	res := l.group.DoChan(key, func() (value interface{}, err error) {
+		// DoChan panics again in another goroutine, which no caller recovers
+		defer func() {
+			if r := recover(); r != nil {
+				value, err = nil, ErrLoadPanicked.Wrap(fmt.Errorf("%v", r), key)
+			}
+		}()
+		ld := l.startLoad(key)
+		defer l.endLoad(key)
+
+		ctx := context.WithoutCancel(ctx)
+		value, err = load(ctx, key)
+		if err != nil {
+			if l.opts.NegativeTTL > 0 && stderrors.Is(err, ErrKeyNotExist) {
+				l.setNegative(key)
+			}
+			return nil, err
+		}
+		l.store(ctx, key, ld, value, ttl)
+		return value, nil
+	})
+	select {
+	case r := <-res:
+		return r.Val, r.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Set stores the value of a key, forgets a negative result and keeps a load
+// in flight from overwriting the value
+func (l *Loader) Set(ctx context.Context, key string, value interface{}, exp ...time.Duration) error {
+	l.forget(key)
+	return l.Handler.Set(ctx, key, value, exp...)
+}
+
+// Delete removes a key, forgets a negative result and keeps a load in flight
+// from storing the key again
+func (l *Loader) Delete(ctx context.Context, key string) error {
+	l.forget(key)
+	return l.Handler.Delete(ctx, key)
+}
+
+// Clear removes every key and forgets the negative results
+func (l *Loader) Clear(ctx context.Context) error {
+	l.mu.Lock()
+	l.negative = make(map[string]time.Time)
+	loads := make([]*loadState, 0, len(l.loading))
+	for _, ld := range l.loading {
+		ld.generation++
+		loads = append(loads, ld)
+	}
+	l.mu.Unlock()
+	for _, ld := range loads {
+		ld.wait()
+	}
+	return l.Handler.Clear(ctx)
+}
+
+func (l *Loader) isNegative(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	expiration, ok := l.negative[key]
+	if ok && time.Now().After(expiration) {
+		delete(l.negative, key)
+		return false
+	}
+	return ok
+}
+
+func (l *Loader) setNegative(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if len(l.negative) >= l.opts.MaxNegative {
+		for k, expiration := range l.negative {
+			if now.After(expiration) {
+				delete(l.negative, k)
+			}
+		}
+		if len(l.negative) >= l.opts.MaxNegative {
+			return
+		}
+	}
+	l.negative[key] = now.Add(l.opts.NegativeTTL)
+}
+
+// forget drops the negative result of a key and keeps a load in flight from
+// storing its value, a value being stored is written before the caller's
+// change
+func (l *Loader) forget(key string) {
+	l.mu.Lock()
+	delete(l.negative, key)
+	ld := l.loading[key]
+	if ld != nil {
+		ld.generation++
+	}
+	l.mu.Unlock()
+	if ld != nil {
+		ld.wait()
+	}
+}
+
+// startLoad tracks the generation of a key, there is a single load per key
+// at a time
+func (l *Loader) startLoad(key string) *loadState {
+	ld := &loadState{}
+	l.mu.Lock()
+	l.loading[key] = ld
+	l.mu.Unlock()
+	return ld
+}
+
+func (l *Loader) endLoad(key string) {
+	l.mu.Lock()
+	delete(l.loading, key)
+	l.mu.Unlock()
+}
+
+// store caches a loaded value unless the key was set or deleted during the
+// load. Only the load of the key is held across the write, so a concurrent
+// Set or Delete of the key either skips it or runs after it, while other keys
+// do not wait for the backend.
+func (l *Loader) store(ctx context.Context, key string, ld *loadState, value interface{}, ttl time.Duration) {
+	ld.storing.Lock()
+	defer ld.storing.Unlock()
+	l.mu.Lock()
+	stale := ld.generation > 0
+	l.mu.Unlock()
+	if stale {
+		return
+	}
+	// A zero expiration applies the default of the backend
+	_ = l.Handler.Set(ctx, key, value, max(ttl, 0))
+}
+
+// wait returns once the loaded value being stored, if any, is written
+func (ld *loadState) wait() {
+	ld.storing.Lock()
+	ld.storing.Unlock()
+}
@@ -0,0 +1,247 @@
+package cache_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/cache"
+	"github.com/kumarabd/gokit/cache/cachetest"
+	"github.com/kumarabd/gokit/cache/inmem"
+	"github.com/kumarabd/gokit/errors"
+)
+
+func newLoader(t *testing.T, opts cache.LoaderOptions) *cache.Loader {
+	h, err := inmem.New(inmem.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache.NewLoader(h, opts)
+}
+
+func TestLoaderConformance(t *testing.T) {
+	cachetest.Run(t, cachetest.Options{}, func(t *testing.T) cache.Handler {
+		return newLoader(t, cache.LoaderOptions{NegativeTTL: time.Minute})
+	})
+}
+
+func TestGetOrLoadDeduplicates(t *testing.T) {
+	l := newLoader(t, cache.LoaderOptions{})
+	var loads int32
+	release := make(chan struct{})
+	load := func(ctx context.Context, key string) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "value of " + key, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := l.GetOrLoad(context.Background(), "user:1", time.Minute, load); err != nil || value != "value of user:1" {
+				t.Errorf("GetOrLoad() = %v, %v", value, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("loads = %d, want 1", loads)
+	}
+	if ttl, err := l.TTL(context.Background(), "user:1"); err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL() of the loaded key = %v, %v", ttl, err)
+	}
+}
+
+func TestGetOrLoadDefaultExpiration(t *testing.T) {
+	ctx := context.Background()
+	load := func(context.Context, string) (interface{}, error) { return "value", nil }
+	for name, opts := range map[string]inmem.Options{
+		"unbounded": {Expiration: time.Minute},
+		"bounded":   {Expiration: time.Minute, MaxEntries: 100},
+	} {
+		h, err := inmem.New(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := cache.NewLoader(h, cache.LoaderOptions{})
+		for _, ttl := range []time.Duration{0, -time.Second} {
+			key := fmt.Sprintf("ttl:%v", ttl)
+			if _, err := l.GetOrLoad(ctx, key, ttl, load); err != nil {
+				t.Fatalf("%s: GetOrLoad() error = %v", name, err)
+			}
+			if got, err := l.TTL(ctx, key); err != nil || got <= 0 || got > time.Minute {
+				t.Errorf("%s: TTL() of a key loaded with ttl %v = %v, %v, want the default expiration", name, ttl, got, err)
+			}
+		}
+	}
+}
+
+func TestGetOrLoadNegative(t *testing.T) {
+	ctx := context.Background()
+	l := newLoader(t, cache.LoaderOptions{NegativeTTL: 50 * time.Millisecond})
+	var loads int32
+	load := func(context.Context, string) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return nil, cache.ErrKeyNotExist
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := l.GetOrLoad(ctx, "user:404", time.Minute, load); !stderrors.Is(err, cache.ErrKeyNotExist) {
+			t.Fatalf("GetOrLoad() error = %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loads = %d, want the negative result cached", loads)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	l.GetOrLoad(ctx, "user:404", time.Minute, load)
+	if loads != 2 {
+		t.Errorf("loads = %d, want a load after the negative TTL", loads)
+	}
+
+	if err := l.Set(ctx, "user:404", "created"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := l.GetOrLoad(ctx, "user:404", time.Minute, load); err != nil || value != "created" {
+		t.Errorf("GetOrLoad() after Set() = %v, %v", value, err)
+	}
+}
+
+func TestGetOrLoadCanceled(t *testing.T) {
+	l := newLoader(t, cache.LoaderOptions{})
+	release := make(chan struct{})
+	load := func(ctx context.Context, key string) (interface{}, error) {
+		<-release
+		return "value", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := l.GetOrLoad(ctx, "user:1", 0, load)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; !stderrors.Is(err, context.Canceled) {
+		t.Errorf("GetOrLoad() with a canceled context error = %v", err)
+	}
+
+	close(release)
+	if value, err := l.GetOrLoad(context.Background(), "user:1", 0, load); err != nil || value != "value" {
+		t.Errorf("GetOrLoad() = %v, %v, want the shared load to complete", value, err)
+	}
+}
+
+func TestGetOrLoadInvalidated(t *testing.T) {
+	ctx := context.Background()
+	for name, invalidate := range map[string]func(l *cache.Loader) error{
+		"Set":    func(l *cache.Loader) error { return l.Set(ctx, "user:1", "new") },
+		"Delete": func(l *cache.Loader) error { return l.Delete(ctx, "user:1") },
+		"Clear":  func(l *cache.Loader) error { return l.Clear(ctx) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			l := newLoader(t, cache.LoaderOptions{})
+			started, release := make(chan struct{}), make(chan struct{})
+			load := func(context.Context, string) (interface{}, error) {
+				close(started)
+				<-release
+				return "old", nil
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				if value, err := l.GetOrLoad(ctx, "user:1", time.Minute, load); err != nil || value != "old" {
+					t.Errorf("GetOrLoad() = %v, %v, want the loaded value", value, err)
+				}
+			}()
+			<-started
+			if err := invalidate(l); err != nil {
+				t.Fatal(err)
+			}
+			close(release)
+			<-done
+
+			value, err := l.Get(ctx, "user:1")
+			if name == "Set" {
+				if err != nil || value != "new" {
+					t.Errorf("Get() = %v, %v, want the value set during the load", value, err)
+				}
+			} else if !stderrors.Is(err, cache.ErrKeyNotExist) {
+				t.Errorf("Get() = %v, %v, want the key invalidated during the load to stay missing", value, err)
+			}
+		})
+	}
+}
+
+// slowSet blocks the writes of a key until release is closed
+type slowSet struct {
+	cache.Handler
+	key     string
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (h *slowSet) Set(ctx context.Context, key string, value interface{}, exp ...time.Duration) error {
+	if key == h.key {
+		close(h.writing)
+		<-h.release
+	}
+	return h.Handler.Set(ctx, key, value, exp...)
+}
+
+func TestGetOrLoadSlowStore(t *testing.T) {
+	ctx := context.Background()
+	backend, err := inmem.New(inmem.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow := &slowSet{Handler: backend, key: "user:1", writing: make(chan struct{}), release: make(chan struct{})}
+	l := cache.NewLoader(slow, cache.LoaderOptions{})
+	load := func(context.Context, string) (interface{}, error) { return "value", nil }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = l.GetOrLoad(ctx, "user:1", time.Minute, load)
+	}()
+	<-slow.writing
+
+	// Other keys do not wait for the write of the loaded value
+	changed := make(chan struct{})
+	go func() {
+		defer close(changed)
+		_ = l.Set(ctx, "user:2", "other")
+		_ = l.Delete(ctx, "user:3")
+		_, _ = l.GetOrLoad(ctx, "user:4", time.Minute, load)
+	}()
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("changes of other keys waited for the write of a loaded value")
+	}
+	close(slow.release)
+	<-done
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	l := newLoader(t, cache.LoaderOptions{})
+	load := func(context.Context, string) (interface{}, error) { panic("boom") }
+	_, err := l.GetOrLoad(context.Background(), "user:1", time.Minute, load)
+	if !errors.HasCode(err, cache.ErrLoadPanicked.Code) {
+		t.Errorf("GetOrLoad() with a panicking load error = %v", err)
+	}
+	if ok, _ := l.Exists(context.Background(), "user:1"); ok {
+		t.Error("the key of a panicking load is cached")
+	}
+}
@@ -0,0 +1,113 @@
+package cache
+
+// Match returns whether key matches the glob pattern, with the syntax of the
+// Redis KEYS command: "*" matches any sequence of characters, separators
+// included, "?" any single character, "[abc]" one of the characters and
+// "[a-z]" one in the range, "[^abc]" none of them, and "\c" the character c.
+// As in Redis, "[!abc]" is not a negation and matches "!", "a", "b" or "c".
+// The only error returned is ErrInvalidPattern.
+func Match(pattern, key string) (bool, error) {
+	p, k := []rune(pattern), []rune(key)
+	if !validPattern(p) {
+		return false, ErrInvalidPattern.New(pattern)
+	}
+
+	// Backtrack to the last star when the rest of the pattern mismatches
+	px, kx := 0, 0
+	starPx, starKx := -1, -1
+	for kx < len(k) {
+		if px < len(p) && p[px] == '*' {
+			starPx, starKx = px, kx
+			px++
+			continue
+		}
+		if px < len(p) {
+			if ok, next := matchOne(p, px, k[kx]); ok {
+				px, kx = next, kx+1
+				continue
+			}
+		}
+		if starPx < 0 {
+			return false, nil
+		}
+		starKx++
+		px, kx = starPx+1, starKx
+	}
+	for px < len(p) && p[px] == '*' {
+		px++
+	}
+	return px == len(p), nil
+}
+
+// matchOne matches the pattern element at px against c and returns the
+// position of the next element
+func matchOne(p []rune, px int, c rune) (bool, int) {
+	switch p[px] {
+	case '?':
+		return true, px + 1
+	case '\\':
+		return p[px+1] == c, px + 2
+	case '[':
+		px++
+		negate := p[px] == '^'
+		if negate {
+			px++
+		}
+		matched := false
+		for first := true; first || p[px] != ']'; first = false {
+			lo := p[px]
+			if lo == '\\' {
+				px++
+				lo = p[px]
+			}
+			hi := lo
+			if px+2 < len(p) && p[px+1] == '-' && p[px+2] != ']' {
+				hi = p[px+2]
+				if hi == '\\' {
+					px++
+					hi = p[px+2]
+				}
+				px += 2
+			}
+			if lo <= c && c <= hi {
+				matched = true
+			}
+			px++
+		}
+		return matched != negate, px + 1
+	default:
+		return p[px] == c, px + 1
+	}
+}
+
+// validPattern returns whether escapes and character classes are complete
+func validPattern(p []rune) bool {
+	for px := 0; px < len(p); px++ {
+		switch p[px] {
+		case '\\':
+			if px++; px == len(p) {
+				return false
+			}
+		case '[':
+			px++
+			if px < len(p) && p[px] == '^' {
+				px++
+			}
+			for first := true; ; first = false {
+				if px == len(p) {
+					return false
+				}
+				if p[px] == ']' && !first {
+					break
+				}
+				if p[px] == '\\' {
+					if px++; px == len(p) {
+						return false
+					}
+				}
+				px++
+			}
+		}
+	}
+	return true
+}
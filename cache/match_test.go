@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/kumarabd/gokit/errors"
+)
+
+func TestMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"*", "", true},
+		{"user:*", "user:1/profile", true},
+		{"user:*:name", "user:1:2:name", true},
+		{"user:*:name", "user:1:email", false},
+		{"h?llo", "héllo", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[!e]llo", "hallo", false},
+		{"h[!e]llo", "hello", true},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[]]llo", "h]llo", true},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{"**a", "bba", true},
+	} {
+		if got, err := Match(tc.pattern, tc.key); err != nil || got != tc.want {
+			t.Errorf("Match(%q, %q) = %v, %v, want %v", tc.pattern, tc.key, got, err, tc.want)
+		}
+	}
+	for _, pattern := range []string{"[a", `a\`, "[", `[a\`} {
+		if _, err := Match(pattern, "a"); !errors.HasCode(err, ErrInvalidPattern.Code) {
+			t.Errorf("Match(%q) error = %v, want ErrInvalidPattern", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec converts the values of the cache to the bytes stored in Redis
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// JSONCodec stores values as JSON, they are read back as the generic JSON
+// types: map[string]interface{}, []interface{}, string, float64 and bool
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec) Unmarshal(data []byte) (interface{}, error) {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// GobCodec stores values with encoding/gob and reads them back with their
+// type, which must be registered with gob.Register unless it is a basic type
+type GobCodec struct{}
+
+func (GobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&value)
+	return buf.Bytes(), err
+}
+
+func (GobCodec) Unmarshal(data []byte) (interface{}, error) {
+	var value interface{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
+
+// StringCodec stores strings and byte slices as they are, values are read
+// back as strings
+type StringCodec struct{}
+
+func (StringCodec) Marshal(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+func (StringCodec) Unmarshal(data []byte) (interface{}, error) {
+	return string(data), nil
+}
@@ -0,0 +1,12 @@
+package redis
+
+import "github.com/kumarabd/gokit/errors"
+
+var (
+	ErrCommandFailed = errors.Register("cache.command_failed", errors.Critical, "Redis command %s failed",
+		"The Redis server could not be reached or rejected the command, check its health, the address and the credentials.")
+	ErrEncodeFailed = errors.Register("cache.encode_failed", errors.Warn, "Failed to encode the value of key %q",
+		"The codec of the cache cannot encode the value, register its type for gob or use a codec supporting it.")
+	ErrDecodeFailed = errors.Register("cache.decode_failed", errors.Warn, "Failed to decode the value of key %q",
+		"The stored value was written with another codec or type, clear the key or align the codecs of the services.")
+)
@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClientOptions(t *testing.T) {
+	opts := Options{
+		Addr:          "cache:6379",
+		MasterName:    "cache",
+		SentinelAddrs: []string{"sentinel-1:26379", "sentinel-2:26379"},
+		Password:      "secret",
+		DB:            2,
+		PoolSize:      20,
+		MinIdleConns:  5,
+		PoolTimeout:   time.Second,
+	}
+	failover := failoverOptions(opts)
+	if failover.MasterName != "cache" || len(failover.SentinelAddrs) != 2 {
+		t.Errorf("failoverOptions() = %+v, want the Sentinel options", failover)
+	}
+	if failover.Password != "secret" || failover.DB != 2 || failover.PoolSize != 20 ||
+		failover.MinIdleConns != 5 || failover.PoolTimeout != time.Second {
+		t.Errorf("failoverOptions() = %+v, want the master and pool options", failover)
+	}
+
+	client := clientOptions(opts)
+	if client.Addr != "cache:6379" || client.Password != "secret" || client.DB != 2 ||
+		client.PoolSize != 20 || client.MinIdleConns != 5 || client.PoolTimeout != time.Second {
+		t.Errorf("clientOptions() = %+v, want the server and pool options", client)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got, want := escape(`svc:[1]*?\`), `svc:\[1\]\*\?\\`; got != want {
+		t.Errorf("escape() = %q, want %q", got, want)
+	}
+}
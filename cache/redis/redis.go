@@ -0,0 +1,306 @@
+// Package redis is a cache.Handler backed by a Redis server, standalone or
+// behind Sentinel
+package redis
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/kumarabd/gokit/cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// scanCount is the number of keys requested per SCAN iteration
+const scanCount = 100
+
+type Options struct {
+	// Addr is the address of a standalone server, ignored with MasterName
+	Addr string `json:"addr,omitempty" yaml:"addr,omitempty"`
+	// MasterName and SentinelAddrs select the master through Sentinel
+	MasterName       string   `json:"master_name,omitempty" yaml:"master_name,omitempty"`
+	SentinelAddrs    []string `json:"sentinel_addrs,omitempty" yaml:"sentinel_addrs,omitempty"`
+	SentinelPassword string   `json:"sentinel_password,omitempty" yaml:"sentinel_password,omitempty"`
+	Username         string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password         string   `json:"password,omitempty" yaml:"password,omitempty"`
+	DB               int      `json:"db,omitempty" yaml:"db,omitempty"`
+
+	// Connection pool, the go-redis defaults when zero
+	PoolSize     int           `json:"pool_size,omitempty" yaml:"pool_size,omitempty"`
+	MinIdleConns int           `json:"min_idle_conns,omitempty" yaml:"min_idle_conns,omitempty"`
+	PoolTimeout  time.Duration `json:"pool_timeout,omitempty" yaml:"pool_timeout,omitempty"`
+	DialTimeout  time.Duration `json:"dial_timeout,omitempty" yaml:"dial_timeout,omitempty"`
+	ReadTimeout  time.Duration `json:"read_timeout,omitempty" yaml:"read_timeout,omitempty"`
+	WriteTimeout time.Duration `json:"write_timeout,omitempty" yaml:"write_timeout,omitempty"`
+
+	// Prefix is prepended to the keys, so services can share a database.
+	// Keys and Clear only see the keys of the prefix.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// Expiration is the default expiration of Set with a zero expiration
+	Expiration time.Duration `json:"expiration,omitempty" yaml:"expiration,omitempty"`
+	// Codec encodes the values, JSONCodec when nil
+	Codec Codec `json:"-" yaml:"-"`
+}
+
+type redis struct {
+	client     goredis.UniversalClient
+	prefix     string
+	expiration time.Duration
+	codec      Codec
+}
+
+// New returns a Redis cache. The client connects lazily, so New does not fail
+// when the server is down. The handler implements io.Closer to release the
+// connection pool.
+func New(opts Options) (cache.Handler, error) {
+	var client goredis.UniversalClient
+	if opts.MasterName != "" {
+		client = goredis.NewFailoverClient(failoverOptions(opts))
+	} else {
+		client = goredis.NewClient(clientOptions(opts))
+	}
+	codec := opts.Codec
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &redis{
+		client:     client,
+		prefix:     opts.Prefix,
+		expiration: opts.Expiration,
+		codec:      codec,
+	}, nil
+}
+
+func clientOptions(opts Options) *goredis.Options {
+	return &goredis.Options{
+		Addr:         opts.Addr,
+		Username:     opts.Username,
+		Password:     opts.Password,
+		DB:           opts.DB,
+		PoolSize:     opts.PoolSize,
+		MinIdleConns: opts.MinIdleConns,
+		PoolTimeout:  opts.PoolTimeout,
+		DialTimeout:  opts.DialTimeout,
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+	}
+}
+
+func failoverOptions(opts Options) *goredis.FailoverOptions {
+	return &goredis.FailoverOptions{
+		MasterName:       opts.MasterName,
+		SentinelAddrs:    opts.SentinelAddrs,
+		SentinelPassword: opts.SentinelPassword,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		DB:               opts.DB,
+		PoolSize:         opts.PoolSize,
+		MinIdleConns:     opts.MinIdleConns,
+		PoolTimeout:      opts.PoolTimeout,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
+	}
+}
+
+func (h *redis) Get(ctx context.Context, key string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, err := h.client.Get(ctx, h.prefix+key).Bytes()
+	if err != nil {
+		return nil, h.commandError("GET", err)
+	}
+	value, err := h.codec.Unmarshal(data)
+	if err != nil {
+		return nil, ErrDecodeFailed.Wrap(err, key)
+	}
+	return value, nil
+}
+
+func (h *redis) Set(ctx context.Context, key string, value interface{}, exp ...time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// go-redis reads a zero expiration as none and a negative one as KEEPTTL
+	var expiration time.Duration
+	if len(exp) > 0 {
+		expiration = exp[0]
+		if expiration == 0 {
+			expiration = h.expiration
+		}
+		expiration = max(expiration, 0)
+	}
+	data, err := h.codec.Marshal(value)
+	if err != nil {
+		return ErrEncodeFailed.Wrap(err, key)
+	}
+	if err := h.client.Set(ctx, h.prefix+key, data, expiration).Err(); err != nil {
+		return h.commandError("SET", err)
+	}
+	return nil
+}
+
+func (h *redis) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := h.client.Del(ctx, h.prefix+key).Err(); err != nil {
+		return h.commandError("DEL", err)
+	}
+	return nil
+}
+
+func (h *redis) Exists(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	n, err := h.client.Exists(ctx, h.prefix+key).Result()
+	if err != nil {
+		return false, h.commandError("EXISTS", err)
+	}
+	return n > 0, nil
+}
+
+func (h *redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	ttl, err := h.client.PTTL(ctx, h.prefix+key).Result()
+	if err != nil {
+		return 0, h.commandError("PTTL", err)
+	}
+	// PTTL replies -2 for a missing key and -1 for a key without expiration
+	switch ttl {
+	case -2:
+		return 0, cache.ErrKeyNotExist
+	case -1:
+		return cache.NoExpiration, nil
+	}
+	return ttl, nil
+}
+
+func (h *redis) Expire(ctx context.Context, key string, exp time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if exp > 0 {
+		ok, err := h.client.PExpire(ctx, h.prefix+key, exp).Result()
+		if err != nil {
+			return h.commandError("PEXPIRE", err)
+		}
+		if !ok {
+			return cache.ErrKeyNotExist
+		}
+		return nil
+	}
+	// PERSIST replies the same for a missing key and a key without expiration
+	if err := h.client.Persist(ctx, h.prefix+key).Err(); err != nil {
+		return h.commandError("PERSIST", err)
+	}
+	ok, err := h.Exists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return cache.ErrKeyNotExist
+	}
+	return nil
+}
+
+func (h *redis) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Validate the pattern with the same rules as the other backends
+	if _, err := cache.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	// SCAN may return a key more than once
+	seen := make(map[string]bool)
+	err := h.scan(ctx, escape(h.prefix)+pattern, func(batch []string) error {
+		for _, key := range batch {
+			key = strings.TrimPrefix(key, h.prefix)
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Clear removes the keys of the prefix, or every key of the database without
+// a prefix
+func (h *redis) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if h.prefix == "" {
+		if err := h.client.FlushDB(ctx).Err(); err != nil {
+			return h.commandError("FLUSHDB", err)
+		}
+		return nil
+	}
+	return h.scan(ctx, escape(h.prefix)+"*", func(batch []string) error {
+		if err := h.client.Del(ctx, batch...).Err(); err != nil {
+			return h.commandError("DEL", err)
+		}
+		return nil
+	})
+}
+
+// Close releases the connections of the pool
+func (h *redis) Close() error {
+	return h.client.Close()
+}
+
+// scan calls fn with the batches of keys matching a pattern
+func (h *redis) scan(ctx context.Context, match string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := h.client.Scan(ctx, cursor, match, scanCount).Result()
+		if err != nil {
+			return h.commandError("SCAN", err)
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// commandError maps the errors of the client to the errors of the cache
+func (h *redis) commandError(command string, err error) error {
+	if stderrors.Is(err, goredis.Nil) {
+		return cache.ErrKeyNotExist
+	}
+	if stderrors.Is(err, context.Canceled) || stderrors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return ErrCommandFailed.Wrap(err, command)
+}
+
+// escape quotes the glob characters of the prefix for SCAN MATCH
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
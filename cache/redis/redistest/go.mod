@@ -0,0 +1,18 @@
+module github.com/kumarabd/gokit/cache/redis/redistest
+
+go 1.22.0
+
+require (
+	github.com/alicebob/miniredis/v2 v2.35.0
+	github.com/kumarabd/gokit v0.0.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/redis/go-redis/v9 v9.7.3 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
+
+replace github.com/kumarabd/gokit => ../../..
@@ -0,0 +1,149 @@
+package redistest_test
+
+import (
+	"context"
+	"encoding/gob"
+	stderrors "errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kumarabd/gokit/cache"
+	"github.com/kumarabd/gokit/cache/cachetest"
+	"github.com/kumarabd/gokit/cache/redis"
+	"github.com/kumarabd/gokit/cache/redis/redistest"
+	"github.com/kumarabd/gokit/errors"
+)
+
+func TestConformance(t *testing.T) {
+	var m *miniredis.Miniredis
+	for name, opts := range map[string]redis.Options{
+		"plain":   {},
+		"prefix":  {Prefix: "svc:[1]*:"},
+		"default": {Expiration: time.Hour},
+	} {
+		t.Run(name, func(t *testing.T) {
+			suite := cachetest.Options{
+				Expiration: opts.Expiration,
+				Wait:       func(d time.Duration) { m.FastForward(d) },
+			}
+			cachetest.Run(t, suite, func(t *testing.T) cache.Handler {
+				var h cache.Handler
+				h, m = redistest.New(t, opts)
+				return h
+			})
+		})
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	ctx := context.Background()
+	h, m := redistest.New(t, redis.Options{Prefix: "orders:"})
+	if err := h.Set(ctx, "1", "pending"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := m.Set("users:1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Get("orders:1"); err != nil || got != `"pending"` {
+		t.Errorf("stored value = %q, %v, want the prefixed JSON", got, err)
+	}
+	if keys, err := h.Keys(ctx, "*"); err != nil || len(keys) != 1 || keys[0] != "1" {
+		t.Errorf("Keys() = %v, %v, want the keys of the prefix only", keys, err)
+	}
+	if err := h.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if !m.Exists("users:1") || m.Exists("orders:1") {
+		t.Errorf("Clear() removed the keys %v, want only the prefixed ones", m.Keys())
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	ctx := context.Background()
+	h, m := redistest.New(t, redis.Options{Expiration: time.Minute})
+	for key, exp := range map[string][]time.Duration{
+		"default": {0},
+		"custom":  {time.Hour},
+		"none":    nil,
+		"never":   {cache.NoExpiration},
+	} {
+		if err := h.Set(ctx, key, "value", exp...); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+	for key, want := range map[string]time.Duration{
+		"default": time.Minute,
+		"custom":  time.Hour,
+		"none":    0,
+		"never":   0,
+	} {
+		if got := m.TTL(key); got != want {
+			t.Errorf("TTL of %q = %v, want %v", key, got, want)
+		}
+	}
+}
+
+type order struct {
+	ID    int
+	Items []string
+}
+
+func TestCodecs(t *testing.T) {
+	gob.Register(order{})
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name  string
+		codec redis.Codec
+		value interface{}
+		want  interface{}
+	}{
+		{"json", nil, map[string]interface{}{"id": 1}, map[string]interface{}{"id": float64(1)}},
+		{"gob", redis.GobCodec{}, order{ID: 1, Items: []string{"book"}}, order{ID: 1, Items: []string{"book"}}},
+		{"string", redis.StringCodec{}, []byte("raw"), "raw"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _ := redistest.New(t, redis.Options{Codec: tc.codec})
+			if err := h.Set(ctx, "key", tc.value); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			value, err := h.Get(ctx, "key")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(value, tc.want) {
+				t.Errorf("Get() = %#v, want %#v", value, tc.want)
+			}
+		})
+	}
+
+	h, m := redistest.New(t, redis.Options{Codec: redis.StringCodec{}})
+	if err := h.Set(ctx, "key", 1); !errors.HasCode(err, redis.ErrEncodeFailed.Code) {
+		t.Errorf("Set() of an unsupported value error = %v", err)
+	}
+	if err := m.Set("raw", "not json"); err != nil {
+		t.Fatal(err)
+	}
+	h, err := redis.New(redis.Options{Addr: m.Addr()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer h.(io.Closer).Close()
+	if _, err := h.Get(ctx, "raw"); !errors.HasCode(err, redis.ErrDecodeFailed.Code) {
+		t.Errorf("Get() of a value of another codec error = %v", err)
+	}
+}
+
+func TestCommandFailed(t *testing.T) {
+	h, m := redistest.New(t, redis.Options{DialTimeout: 100 * time.Millisecond})
+	m.Close()
+	_, err := h.Get(context.Background(), "key")
+	if !errors.HasCode(err, redis.ErrCommandFailed.Code) {
+		t.Errorf("Get() with the server down error = %v", err)
+	}
+	if stderrors.Is(err, cache.ErrKeyNotExist) {
+		t.Error("Get() with the server down reported a missing key")
+	}
+}
@@ -0,0 +1,28 @@
+// Package redistest runs Redis caches against an in-process stand-in, so
+// tests do not need a Redis server
+package redistest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kumarabd/gokit/cache"
+	"github.com/kumarabd/gokit/cache/redis"
+)
+
+// New returns a Redis cache connected to a new in-process server, both are
+// closed when the test ends. Expirations only advance with
+// Miniredis.FastForward, the Addr and Sentinel options are ignored.
+func New(t testing.TB, opts redis.Options) (cache.Handler, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	opts.Addr = m.Addr()
+	opts.MasterName, opts.SentinelAddrs = "", nil
+	h, err := redis.New(opts)
+	if err != nil {
+		t.Fatalf("redis.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = h.(io.Closer).Close() })
+	return h, m
+}
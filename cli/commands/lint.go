@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+
+	"github.com/kumarabd/gokit/cli/errlint"
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+var LintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check code against the toolkit conventions",
+	Long: `Check code against the toolkit conventions.
+
+Examples:
+  gokit lint errors
+  gokit lint errors ./internal/...`,
+}
+
+var lintErrorsCmd = &cobra.Command{
+	Use:   "errors [packages]",
+	Short: "Check the use of the errors package",
+	Long: `Check the use of the errors package: empty or duplicate error codes,
+codes used with different severities, GetCode and GetSeverity called on
+values that may not be errors of the kit, and errors created inside loops.
+
+Packages default to ./...`,
+	SilenceUsage: true,
+	RunE:         runLintErrors,
+}
+
+var (
+	lintDir   string
+	lintTests bool
+)
+
+func init() {
+	lintErrorsCmd.Flags().StringVarP(&lintDir, "dir", "d", "", "Directory of the module to check (default: current directory)")
+	lintErrorsCmd.Flags().BoolVar(&lintTests, "tests", false, "Check test files as well")
+	LintCmd.AddCommand(lintErrorsCmd)
+}
+
+func runLintErrors(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: lintDir, Tests: lintTests}
+	pkgs, err := packages.Load(cfg, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return fmt.Errorf("packages contain errors")
+	}
+
+	graph, err := checker.Analyze([]*analysis.Analyzer{errlint.Analyzer}, pkgs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to analyze packages: %w", err)
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return fmt.Errorf("failed to analyze %s: %w", act.Package.PkgPath, act.Err)
+		}
+	}
+
+	count := printDiagnostics(cmd.OutOrStdout(), graph)
+	if count > 0 {
+		return fmt.Errorf("%d problems found", count)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "✅ No problems found")
+	return nil
+}
+
+// printDiagnostics writes the diagnostics of the graph and the conflicts
+// between its packages sorted by position. Test variants of a package report
+// the same diagnostics more than once.
+func printDiagnostics(w io.Writer, graph *checker.Graph) int {
+	type diagnostic struct {
+		pos     token.Position
+		message string
+	}
+	seen := make(map[diagnostic]bool)
+	var diags []diagnostic
+	add := func(fset *token.FileSet, diag analysis.Diagnostic) {
+		d := diagnostic{pos: fset.Position(diag.Pos), message: diag.Message}
+		if !seen[d] {
+			seen[d] = true
+			diags = append(diags, d)
+		}
+	}
+	for _, act := range graph.Roots {
+		for _, diag := range act.Diagnostics {
+			add(act.Package.Fset, diag)
+		}
+	}
+	if len(graph.Roots) > 0 {
+		// The packages of a load share a file set
+		for _, diag := range errlint.Conflicts(graph) {
+			add(graph.Roots[0].Package.Fset, diag)
+		}
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		a, b := diags[i].pos, diags[j].pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	for _, d := range diags {
+		fmt.Fprintf(w, "%s: %s\n", d.pos, d.message)
+	}
+	return len(diags)
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lintSource = `package lint
+
+import "github.com/kumarabd/gokit/errors"
+
+var ErrMissing = errors.Register("lint.missing", errors.Warn, "Missing %v", "")
+
+func Check(names []string) error {
+	for _, name := range names {
+		if name == "" {
+			return errors.New("lint.missing", errors.Warn, "missing name")
+		}
+	}
+	return nil
+}
+`
+
+func TestLintErrors(t *testing.T) {
+	kit, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(kit, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	mod := "module example.com/lint\n\ngo 1.22.0\n\nrequire github.com/kumarabd/gokit v0.0.0\n\nreplace github.com/kumarabd/gokit => " + kit + "\n"
+	for name, data := range map[string][]byte{"go.mod": []byte(mod), "go.sum": sum, "lint.go": []byte(lintSource)} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Let the go command add the requirements of the kit to go.mod
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	out := &bytes.Buffer{}
+	lintErrorsCmd.SetOut(out)
+	lintDir = dir
+	defer func() { lintDir = "" }()
+	err = runLintErrors(lintErrorsCmd, nil)
+	if err == nil || err.Error() != "1 problems found" {
+		t.Fatalf("lint error = %v, output %q", err, out.String())
+	}
+	if !strings.Contains(out.String(), `lint.go:10:22: duplicate error code "lint.missing"`) {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
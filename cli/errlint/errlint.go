@@ -0,0 +1,349 @@
+// Package errlint defines an analyzer reporting violations of the error
+// conventions of the kit
+package errlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// ErrorsPath is the import path of the errors package of the kit
+const ErrorsPath = "github.com/kumarabd/gokit/errors"
+
+// kitPath prefixes the packages of the kit, whose codes are collected but
+// which are not reported on since they handle arbitrary errors by design
+const kitPath = "github.com/kumarabd/gokit/"
+
+// Analyzer reports errors created with empty or duplicate codes, codes used
+// with different severities, GetCode and GetSeverity called on values that
+// may not be errors of the kit, and errors created inside loops. Codes are
+// compared with the codes of the analyzed package and of the packages it
+// imports, Conflicts compares the codes of packages not importing each other.
+// Packages of the kit itself are not reported on.
+var Analyzer = &analysis.Analyzer{
+	Name:       "gokiterrors",
+	Doc:        "check the error conventions of github.com/kumarabd/gokit/errors",
+	URL:        "https://github.com/kumarabd/gokit/blob/master/docs/error-handling.md#static-analysis",
+	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes:  []analysis.Fact{new(codes)},
+	ResultType: reflect.TypeOf(new(codes)),
+	Run:        run,
+}
+
+// codeSite is a call creating or registering an error with a constant code
+type codeSite struct {
+	Code     string
+	Severity string
+	Position string
+	// Unique tells whether the code must not be used by another site, true
+	// for New and Register and false for Wrap
+	Unique bool
+	// pos is the position of the call in the file set of the pass, it is
+	// not part of the fact and only set in the result of the pass
+	pos token.Pos
+}
+
+// codes is the package fact listing the sites of a package
+type codes struct {
+	Sites []codeSite
+}
+
+func (*codes) AFact() {}
+
+func (c *codes) String() string { return fmt.Sprintf("codes(%d)", len(c.Sites)) }
+
+// linter runs the checks of a pass
+type linter struct {
+	pass  *analysis.Pass
+	quiet bool
+}
+
+func (l *linter) reportf(pos token.Pos, format string, args ...interface{}) {
+	if !l.quiet {
+		l.pass.Reportf(pos, format, args...)
+	}
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !imports(pass.Pkg, ErrorsPath) {
+		return new(codes), nil
+	}
+	l := &linter{pass: pass, quiet: strings.HasPrefix(pass.Pkg.Path()+"/", kitPath)}
+
+	seen := make(map[string]codeSite)
+	for _, fact := range pass.AllPackageFacts() {
+		for _, site := range fact.Fact.(*codes).Sites {
+			if _, ok := seen[site.Code]; !ok || site.Unique {
+				seen[site.Code] = site
+			}
+		}
+	}
+
+	var sites []codeSite
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != ErrorsPath {
+			return true
+		}
+
+		switch name := fn.Name(); {
+		case isMethod(fn, "Definition") && (name == "New" || name == "Wrap"):
+			l.checkLoop(call, stack)
+		case isMethod(fn, ""):
+			// Methods of the other types, such as Error.With
+		case name == "New", name == "Wrap", name == "Register":
+			if name != "Register" {
+				l.checkLoop(call, stack)
+			}
+			codeArg, severityArg := 0, 1
+			if name == "Wrap" {
+				codeArg, severityArg = 1, 2
+			}
+			if site, ok := l.checkCode(call, call.Args[codeArg], call.Args[severityArg], name != "Wrap", seen); ok {
+				sites = append(sites, site)
+			}
+		case name == "GetCode", name == "GetSeverity":
+			l.checkSubject(call, name, stack)
+		}
+		return true
+	})
+
+	result := &codes{Sites: sites}
+	if len(sites) > 0 {
+		pass.ExportPackageFact(result)
+	}
+	return result, nil
+}
+
+// Conflicts reports the duplicate codes and the codes used with different
+// severities across the root packages of a graph analyzed with Analyzer that
+// do not import each other, which the passes of the packages cannot compare.
+// Sites of the kit itself are compared but not reported on.
+func Conflicts(graph *checker.Graph) []analysis.Diagnostic {
+	type rootSite struct {
+		codeSite
+		pkg *packages.Package
+	}
+	var sites []rootSite
+	seen := make(map[string]bool)
+	for _, act := range graph.Roots {
+		result, ok := act.Result.(*codes)
+		if act.Analyzer != Analyzer || !ok {
+			continue
+		}
+		// Test variants of a package list the same sites again
+		for _, site := range result.Sites {
+			if !seen[site.Position] {
+				seen[site.Position] = true
+				sites = append(sites, rootSite{codeSite: site, pkg: act.Package})
+			}
+		}
+	}
+
+	var diags []analysis.Diagnostic
+	byCode := make(map[string][]rootSite)
+	for _, site := range sites {
+		for _, prev := range byCode[site.Code] {
+			if related(site.pkg, prev.pkg) || strings.HasPrefix(site.pkg.PkgPath+"/", kitPath) {
+				continue
+			}
+			if site.Unique && prev.Unique {
+				diags = append(diags, analysis.Diagnostic{Pos: site.pos, Message: fmt.Sprintf("duplicate error code %q, also used at %s", site.Code, prev.Position)})
+				break
+			}
+			if site.Severity != "" && prev.Severity != "" && site.Severity != prev.Severity {
+				diags = append(diags, analysis.Diagnostic{Pos: site.pos, Message: fmt.Sprintf("error code %q used with severity %q, %s uses %q", site.Code, site.Severity, prev.Position, prev.Severity)})
+				break
+			}
+		}
+		byCode[site.Code] = append(byCode[site.Code], site)
+	}
+	return diags
+}
+
+// related returns whether two packages are the same or one of them imports
+// the other, directly or not, in which case a pass compared their codes
+func related(a, b *packages.Package) bool {
+	return a.PkgPath == b.PkgPath || dependsOn(a, b.PkgPath, map[string]bool{}) || dependsOn(b, a.PkgPath, map[string]bool{})
+}
+
+func dependsOn(pkg *packages.Package, path string, visited map[string]bool) bool {
+	for _, imp := range pkg.Imports {
+		if imp.PkgPath == path {
+			return true
+		}
+		if !visited[imp.ID] {
+			visited[imp.ID] = true
+			if dependsOn(imp, path, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkCode reports empty and duplicate codes and codes used with another
+// severity, and returns the site of the call when its code is a constant
+func (l *linter) checkCode(call *ast.CallExpr, codeExpr, severityExpr ast.Expr, unique bool, seen map[string]codeSite) (codeSite, bool) {
+	code, ok := stringConstant(l.pass, codeExpr)
+	if !ok {
+		return codeSite{}, false
+	}
+	if code == "" {
+		l.reportf(codeExpr.Pos(), "error created with an empty code")
+		return codeSite{}, false
+	}
+
+	site := codeSite{Code: code, Position: l.pass.Fset.Position(call.Pos()).String(), Unique: unique, pos: call.Pos()}
+	site.Severity, _ = stringConstant(l.pass, severityExpr)
+
+	prev, ok := seen[code]
+	switch {
+	case !ok:
+	case unique && prev.Unique:
+		l.reportf(codeExpr.Pos(), "duplicate error code %q, also used at %s", code, prev.Position)
+		return site, true
+	case site.Severity != "" && prev.Severity != "" && site.Severity != prev.Severity:
+		l.reportf(severityExpr.Pos(), "error code %q used with severity %q, %s uses %q", code, site.Severity, prev.Position, prev.Severity)
+	}
+	if !ok || (unique && !prev.Unique) {
+		seen[code] = site
+	}
+	return site, true
+}
+
+// checkLoop reports errors created inside the body of a loop, unless they
+// are returned
+func (l *linter) checkLoop(call *ast.CallExpr, stack []ast.Node) {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch node := stack[i].(type) {
+		case *ast.FuncLit, *ast.FuncDecl, *ast.ReturnStmt:
+			return
+		case *ast.ForStmt:
+			if stack[i+1] == node.Body {
+				l.reportf(call.Pos(), "error created inside a loop, create it once outside the loop")
+				return
+			}
+		case *ast.RangeStmt:
+			if stack[i+1] == node.Body {
+				l.reportf(call.Pos(), "error created inside a loop, create it once outside the loop")
+				return
+			}
+		}
+	}
+}
+
+// checkSubject reports GetCode and GetSeverity calls on values that may not
+// be errors of the kit, unless an enclosing if checks them with errors.Is,
+// errors.As or errors.HasCode
+func (l *linter) checkSubject(call *ast.CallExpr, name string, stack []ast.Node) {
+	if len(call.Args) != 1 || isKitError(l.pass.TypesInfo.TypeOf(call.Args[0])) {
+		return
+	}
+
+	subject := referent(l.pass, call.Args[0])
+	for i := len(stack) - 2; i >= 0 && subject != nil; i-- {
+		if node, ok := stack[i].(*ast.IfStmt); ok && stack[i+1] == node.Body && guards(l.pass, node.Cond, subject) {
+			return
+		}
+	}
+	l.reportf(call.Pos(), "%s on a value that may not be an *errors.Error, check it with errors.Is first", name)
+}
+
+// guards returns whether cond checks that obj holds an error of the kit
+func guards(pass *analysis.Pass, cond ast.Expr, obj types.Object) bool {
+	found := false
+	ast.Inspect(cond, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found || len(call.Args) == 0 {
+			return !found
+		}
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if ok && fn.Pkg() != nil && fn.Pkg().Path() == ErrorsPath && !isMethod(fn, "") {
+			switch fn.Name() {
+			case "Is", "As", "HasCode":
+				found = referent(pass, call.Args[0]) == obj
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// referent returns the variable an expression refers to, if any
+func referent(pass *analysis.Pass, expr ast.Expr) types.Object {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return pass.TypesInfo.ObjectOf(expr)
+	case *ast.SelectorExpr:
+		return pass.TypesInfo.ObjectOf(expr.Sel)
+	}
+	return nil
+}
+
+// isKitError returns whether t is *errors.Error or *errors.Multi
+func isKitError(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != ErrorsPath {
+		return false
+	}
+	return named.Obj().Name() == "Error" || named.Obj().Name() == "Multi"
+}
+
+// isMethod returns whether fn is a method, of the named type if not empty
+func isMethod(fn *types.Func, named string) bool {
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	if named == "" {
+		return true
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Name() == named
+}
+
+func stringConstant(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func imports(pkg *types.Package, path string) bool {
+	if pkg.Path() == path {
+		return true
+	}
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == path {
+			return true
+		}
+	}
+	return false
+}
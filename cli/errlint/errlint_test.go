@@ -0,0 +1,51 @@
+package errlint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "shared", "service")
+}
+
+func TestConflicts(t *testing.T) {
+	// billing and shipping do not import each other
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  filepath.Join(analysistest.TestData(), "src"),
+		Env:  append(os.Environ(), "GOPATH="+analysistest.TestData(), "GO111MODULE=off", "GOPROXY=off"),
+	}
+	pkgs, err := packages.Load(cfg, "billing", "shipping", "shared", "service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := checker.Analyze([]*analysis.Analyzer{Analyzer}, pkgs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, diag := range Conflicts(graph) {
+		pos := pkgs[0].Fset.Position(diag.Pos)
+		got = append(got, filepath.Base(pos.Filename)+": "+diag.Message)
+	}
+	want := []string{
+		`shipping.go: duplicate error code "order.declined", also used at ` + filepath.Join(analysistest.TestData(), "src", "billing", "billing.go") + `:9:19`,
+		`shipping.go: error code "order.unavailable" used with severity "critical", ` + filepath.Join(analysistest.TestData(), "src", "billing", "billing.go") + `:12:9 uses "warn"`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Conflicts() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("conflict %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
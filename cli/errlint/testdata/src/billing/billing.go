@@ -0,0 +1,13 @@
+package billing
+
+import (
+	"io"
+
+	"github.com/kumarabd/gokit/errors"
+)
+
+var ErrDeclined = errors.Register("order.declined", errors.Warn, "Order %v declined", "")
+
+func Charge() error {
+	return errors.Wrap(io.EOF, "order.unavailable", errors.Warn, "charge")
+}
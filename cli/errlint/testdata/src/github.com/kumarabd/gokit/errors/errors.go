@@ -0,0 +1,49 @@
+// Package errors is a stub of the errors package of the kit
+package errors
+
+type Severity string
+
+const (
+	Warn     Severity = "warn"
+	Critical Severity = "critical"
+)
+
+type Error struct {
+	Code     string
+	Severity Severity
+}
+
+func (e *Error) Error() string { return e.Code }
+
+func (e *Error) With(key string, value interface{}) *Error { return e }
+
+type Multi struct{ errs []error }
+
+func (m *Multi) Error() string { return "" }
+
+type Definition struct {
+	Code     string
+	Severity Severity
+}
+
+func (d *Definition) New(args ...interface{}) *Error { return &Error{Code: d.Code} }
+
+func Register(code string, severity Severity, template, docs string) *Definition {
+	return &Definition{Code: code, Severity: severity}
+}
+
+func New(code string, severity Severity, description ...interface{}) *Error {
+	return &Error{Code: code, Severity: severity}
+}
+
+func Wrap(err error, code string, severity Severity, description ...interface{}) *Error {
+	return &Error{Code: code, Severity: severity}
+}
+
+func GetCode(err error) string { return "" }
+
+func GetSeverity(err error) Severity { return "" }
+
+func Is(err error) bool { return false }
+
+func HasCode(err error, code string) bool { return false }
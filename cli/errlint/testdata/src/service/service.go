@@ -0,0 +1,52 @@
+package service // want package:"codes\\(8\\)"
+
+import (
+	"io"
+
+	"github.com/kumarabd/gokit/errors"
+	"shared"
+)
+
+const codeLocked = "user.locked"
+
+func Lookup(id string) error {
+	if id == "" {
+		return errors.New("user.not_found", errors.Warn, "missing") // want `duplicate error code "user.not_found", also used at .*shared.go`
+	}
+	return errors.New(codeLocked, errors.Warn, "locked")
+}
+
+func Unlock() error {
+	return errors.Wrap(io.EOF, codeLocked, errors.Critical, "unlock") // want `error code "user.locked" used with severity "critical", .* uses "warn"`
+}
+
+func Fetch() error {
+	return errors.Wrap(io.EOF, "user.not_found", errors.Warn, "fetch")
+}
+
+func Validate(ids []string) error {
+	var errs []error
+	for _, id := range ids {
+		if id == "" {
+			errs = append(errs, shared.ErrNotFound.New(id)) // want `error created inside a loop`
+			continue
+		}
+		if len(id) > 64 {
+			return errors.New("user.invalid_id", errors.Warn, id)
+		}
+		func() { _ = errors.New("user.deferred", errors.Warn) }()
+	}
+	for i := 0; i < len(errs); i++ {
+		errs[i] = errors.Wrap(errs[i], "user.batch", errors.Warn).With("index", i) // want `error created inside a loop`
+	}
+	return nil
+}
+
+func Describe(err error) (string, errors.Severity) {
+	if errors.Is(err) {
+		return errors.GetCode(err), errors.GetSeverity(err)
+	}
+	kit := errors.New("user.describe", errors.Warn)
+	_ = errors.GetCode(kit)
+	return errors.GetCode(err), errors.GetSeverity(io.EOF) // want `GetCode on a value that may not be an \*errors.Error` `GetSeverity on a value that may not be an \*errors.Error`
+}
@@ -0,0 +1,9 @@
+package shared // want package:"codes\\(2\\)"
+
+import "github.com/kumarabd/gokit/errors"
+
+var (
+	ErrNotFound = errors.Register("user.not_found", errors.Warn, "User %v not found", "")
+	ErrEmpty    = errors.Register("", errors.Warn, "Empty", "")               // want `error created with an empty code`
+	ErrTwice    = errors.Register("user.not_found", errors.Warn, "Again", "") // want `duplicate error code "user.not_found"`
+)
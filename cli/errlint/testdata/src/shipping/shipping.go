@@ -0,0 +1,13 @@
+package shipping
+
+import (
+	"io"
+
+	"github.com/kumarabd/gokit/errors"
+)
+
+var ErrDeclined = errors.Register("order.declined", errors.Warn, "Shipment %v declined", "")
+
+func Ship() error {
+	return errors.Wrap(io.EOF, "order.unavailable", errors.Critical, "ship")
+}
@@ -32,7 +32,9 @@ Features:
 Examples:
   gokit new service --name user-service --template http
   gokit add monitoring --service user-service
-  gokit add tracing --service user-service`,
+  gokit add tracing --service user-service
+  gokit audit verify ./audit.log
+  gokit lint errors ./...`,
 }
 
 func init() {
@@ -40,6 +42,8 @@ func init() {
 	rootCmd.AddCommand(commands.NewServiceCmd)
 	rootCmd.AddCommand(commands.AddFeatureCmd)
 	rootCmd.AddCommand(commands.VersionCmd)
+	rootCmd.AddCommand(commands.AuditCmd)
+	rootCmd.AddCommand(commands.LintCmd)
 
 	// Set version information
 	rootCmd.Version = version
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+	errhttp "github.com/kumarabd/gokit/errors/http"
 )
 
 const (
@@ -16,16 +20,24 @@ type Options struct {
 	URL     string
 	Headers map[string][]string
 	Params  map[string][]string
+	// MaxRetries is the number of times a request failing with a retryable
+	// error is sent again, requests are not retried by default
+	MaxRetries int `json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	// Backoff is the delay before the first retry, doubled on each attempt
+	Backoff time.Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
 }
 
 type Handler struct {
-	client *http.Client
-	req    *http.Request
+	client     *http.Client
+	req        *http.Request
+	maxRetries int
+	backoff    time.Duration
 }
 
 type Response struct {
 	Code   int
 	Status string
+	Header http.Header
 	Data   []byte
 }
 
@@ -57,17 +69,53 @@ func New(opts Options) (*Handler, error) {
 
 	req.Header = opts.Headers
 
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+
 	return &Handler{
-		client: client,
-		req:    req,
+		client:     client,
+		req:        req,
+		maxRetries: opts.MaxRetries,
+		backoff:    backoff,
 	}, nil
 }
 
+// Do sends the request. Requests failing with a retryable error, see
+// errors.IsRetryable, are retried up to MaxRetries times with an exponential
+// backoff, waiting at least the Retry-After delay of throttled responses.
 func (h *Handler) Do() (*Response, error) {
+	delay := h.backoff
+	for attempt := 0; ; attempt++ {
+		res, err := h.do()
+		failure := err
+		if err == nil {
+			failure = res.Err()
+		}
+		if failure == nil || attempt >= h.maxRetries || !errors.IsRetryable(failure) {
+			return res, err
+		}
+
+		wait := delay
+		if retryAfter := errors.GetRetryAfter(failure); retryAfter > wait {
+			wait = retryAfter
+		}
+		select {
+		case <-time.After(wait):
+		case <-h.req.Context().Done():
+			return res, err
+		}
+		delay *= 2
+	}
+}
+
+func (h *Handler) do() (*Response, error) {
 	res, err := h.client.Do(h.req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
 	data, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -77,6 +125,13 @@ func (h *Handler) Do() (*Response, error) {
 	return &Response{
 		Code:   res.StatusCode,
 		Status: res.Status,
+		Header: res.Header,
 		Data:   data,
 	}, nil
 }
+
+// Err returns the error carried by a failed response, problem details bodies
+// are decoded into *errors.Error
+func (r *Response) Err() error {
+	return errhttp.DecodeResponse(r.Code, r.Header, r.Data)
+}
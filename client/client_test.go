@@ -0,0 +1,43 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+)
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 3:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte("late"))
+		}
+	}))
+	defer server.Close()
+
+	h, err := New(Options{Type: POST, URL: server.URL, MaxRetries: 5, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	res, err := h.Do()
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	// The 404 is permanent and stops the retries
+	if res.Code != http.StatusNotFound || atomic.LoadInt32(&calls) != 3 || !errors.IsPermanent(res.Err()) {
+		t.Errorf("Do() = %d after %d calls, class %q", res.Code, calls, errors.GetClass(res.Err()))
+	}
+}
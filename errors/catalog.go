@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Definition describes a registered error code
+type Definition struct {
+	Code     string   `json:"code" yaml:"code"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	// Template is the fmt format of the error description
+	Template string `json:"template" yaml:"template"`
+	// Docs explains the cause of the error and how to resolve it
+	Docs string `json:"docs,omitempty" yaml:"docs,omitempty"`
+	// Class is the classification of the errors created from the definition
+	Class Class `json:"class,omitempty" yaml:"class,omitempty"`
+}
+
+var catalog = struct {
+	sync.RWMutex
+	definitions map[string]*Definition
+}{definitions: make(map[string]*Definition)}
+
+// Register adds an error code to the catalog. It is meant to be called from
+// package level variables and panics on an empty or duplicate code.
+func Register(code string, severity Severity, template, docs string) *Definition {
+	if code == "" {
+		panic("errors: registering an empty code")
+	}
+
+	catalog.Lock()
+	defer catalog.Unlock()
+	if _, ok := catalog.definitions[code]; ok {
+		panic("errors: duplicate registration of code " + code)
+	}
+	def := &Definition{Code: code, Severity: severity, Template: template, Docs: docs}
+	catalog.definitions[code] = def
+	return def
+}
+
+// New instantiates an error of the definition, args fill the template
+func (d *Definition) New(args ...interface{}) *Error {
+	e := newError(nil, d.Code, d.Severity, []interface{}{d.format(args)})
+	e.Class = d.class()
+	return e
+}
+
+// Wrap instantiates an error of the definition caused by err
+func (d *Definition) Wrap(err error, args ...interface{}) *Error {
+	e := newError(err, d.Code, d.Severity, []interface{}{d.format(args)})
+	e.Class = d.class()
+	return e
+}
+
+func (d *Definition) class() Class {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	return d.Class
+}
+
+func (d *Definition) format(args []interface{}) string {
+	if len(args) == 0 {
+		return d.Template
+	}
+	return fmt.Sprintf(d.Template, args...)
+}
+
+// Lookup returns the definition registered for code
+func Lookup(code string) (*Definition, bool) {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	def, ok := catalog.definitions[code]
+	return def, ok
+}
+
+// Catalog returns every registered definition sorted by code
+func Catalog() []Definition {
+	catalog.RLock()
+	defer catalog.RUnlock()
+	out := make([]Definition, 0, len(catalog.definitions))
+	for _, def := range catalog.definitions {
+		out = append(out, *def)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// ExportJSON writes the catalog as a JSON array
+func ExportJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Catalog())
+}
+
+// ExportMarkdown writes the catalog as a Markdown table
+func ExportMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Code | Severity | Message | Description |\n")
+	b.WriteString("|------|----------|---------|-------------|\n")
+	for _, def := range Catalog() {
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", def.Code, def.Severity, markdownCell(def.Template), markdownCell(def.Docs))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+var errTestQuota = Register("test.quota_exceeded", Warn, "Quota of %s exceeded by %d",
+	"The tenant used more than its quota.\nRaise the quota | wait for the reset.")
+
+func TestRegisteredDefinitions(t *testing.T) {
+	err := errTestQuota.Wrap(io.EOF, "acme", 3)
+	if err.Code != "test.quota_exceeded" || err.Severity != Warn || err.Error() != "Quota of acme exceeded by 3: EOF" {
+		t.Errorf("Wrap() = %q (%q, %q)", err.Error(), err.Code, err.Severity)
+	}
+	if def, ok := Lookup("test.quota_exceeded"); !ok || def != errTestQuota {
+		t.Errorf("Lookup() = %v, %v", def, ok)
+	}
+
+	for _, code := range []string{"test.quota_exceeded", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) should panic", code)
+				}
+			}()
+			Register(code, Alert, "again", "")
+		}()
+	}
+}
+
+func TestExportCatalog(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	var defs []Definition
+	if err := json.Unmarshal(buf.Bytes(), &defs); err != nil || len(defs) == 0 {
+		t.Fatalf("ExportJSON() = %s, %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	if err := ExportMarkdown(&buf); err != nil {
+		t.Fatalf("ExportMarkdown() error = %v", err)
+	}
+	row := "| `test.quota_exceeded` | warn | Quota of %s exceeded by %d | The tenant used more than its quota.<br>Raise the quota \\| wait for the reset. |"
+	if !strings.Contains(buf.String(), row) {
+		t.Errorf("ExportMarkdown() = %s", buf.String())
+	}
+}
@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+const (
+	// UnclassifiedClass is the class of errors without classification
+	UnclassifiedClass Class = ""
+	// RetryableClass marks errors which may succeed when retried as is
+	RetryableClass Class = "retryable"
+	// TemporaryClass marks errors caused by a transient condition, such as a timeout
+	TemporaryClass Class = "temporary"
+	// PermanentClass marks errors which will fail again when retried
+	PermanentClass Class = "permanent"
+	// ThrottledClass marks errors caused by rate limiting, retry after RetryAfter
+	ThrottledClass Class = "throttled"
+)
+
+// Class tells callers whether an operation failing with an error can be retried
+type Class string
+
+// WithClass returns a copy of the error with the classification
+func (e *Error) WithClass(class Class) *Error {
+	out := *e
+	out.Class = class
+	return &out
+}
+
+// WithRetryAfter returns a copy of the error classified as throttled, to be
+// retried after the delay
+func (e *Error) WithRetryAfter(delay time.Duration) *Error {
+	out := *e
+	out.Class = ThrottledClass
+	out.RetryAfter = delay
+	return &out
+}
+
+// Classify sets the classification of the errors created from the definition
+func (d *Definition) Classify(class Class) *Definition {
+	catalog.Lock()
+	defer catalog.Unlock()
+	d.Class = class
+	return d
+}
+
+// GetClass returns the classification of the first classified error object
+// in the chain, or infers it for common standard library errors. A Multi is
+// retryable only when all its members are.
+func GetClass(err error) Class {
+	for e := err; e != nil; e = Unwrap(e) {
+		switch obj := e.(type) {
+		case *Error:
+			if obj != nil && obj.Class != UnclassifiedClass {
+				return obj.Class
+			}
+		case *Multi:
+			return obj.Class()
+		}
+	}
+	return inferClass(err)
+}
+
+// inferClass classifies common standard library errors
+func inferClass(err error) Class {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return UnclassifiedClass
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return TemporaryClass
+	case stderrors.Is(err, context.Canceled):
+		return PermanentClass
+	case stderrors.As(err, &netErr) && netErr.Timeout():
+		return TemporaryClass
+	case stderrors.Is(err, syscall.ECONNREFUSED), stderrors.Is(err, syscall.ECONNRESET),
+		stderrors.Is(err, syscall.ECONNABORTED), stderrors.Is(err, syscall.EPIPE):
+		return RetryableClass
+	}
+	return UnclassifiedClass
+}
+
+// IsRetryable reports whether the operation failing with err can be retried,
+// that is err is retryable, temporary or throttled
+func IsRetryable(err error) bool {
+	switch GetClass(err) {
+	case RetryableClass, TemporaryClass, ThrottledClass:
+		return true
+	}
+	return false
+}
+
+// IsTemporary reports whether err is caused by a transient condition
+func IsTemporary(err error) bool {
+	return GetClass(err) == TemporaryClass
+}
+
+// IsPermanent reports whether err will happen again when retried
+func IsPermanent(err error) bool {
+	return GetClass(err) == PermanentClass
+}
+
+// IsThrottled reports whether err is caused by rate limiting
+func IsThrottled(err error) bool {
+	return GetClass(err) == ThrottledClass
+}
+
+// GetRetryAfter returns the delay of the first error object in the chain
+// carrying one, 0 otherwise
+func GetRetryAfter(err error) time.Duration {
+	for e := err; e != nil; e = Unwrap(e) {
+		switch obj := e.(type) {
+		case *Error:
+			if obj != nil && obj.RetryAfter > 0 {
+				return obj.RetryAfter
+			}
+		case *Multi:
+			var delay time.Duration
+			for _, member := range obj.Errors() {
+				if d := GetRetryAfter(member); d > delay {
+					delay = d
+				}
+			}
+			return delay
+		}
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var errTestUpstream = Register("test.upstream_unavailable", Critical, "Upstream unavailable", "").Classify(TemporaryClass)
+
+func TestClassification(t *testing.T) {
+	throttled := New("api.rate_limited", Warn, "Rate limited").WithRetryAfter(2 * time.Second)
+	for _, tc := range []struct {
+		err       error
+		class     Class
+		retryable bool
+	}{
+		{New("", Warn, "plain"), UnclassifiedClass, false},
+		{New("", Warn, "bad input").WithClass(PermanentClass), PermanentClass, false},
+		{fmt.Errorf("call: %w", errTestUpstream.New()), TemporaryClass, true},
+		{fmt.Errorf("call: %w", throttled), ThrottledClass, true},
+		{Wrap(context.DeadlineExceeded, "", Alert, "Timed out"), TemporaryClass, true},
+		{context.Canceled, PermanentClass, false},
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, RetryableClass, true},
+		{&net.DNSError{Err: "timeout", IsTimeout: true}, TemporaryClass, true},
+		{Combine(errTestUpstream.New(), throttled), ThrottledClass, true},
+		{Combine(errTestUpstream.New(), context.Canceled), PermanentClass, false},
+		{nil, UnclassifiedClass, false},
+	} {
+		if got := GetClass(tc.err); got != tc.class || IsRetryable(tc.err) != tc.retryable {
+			t.Errorf("GetClass(%v) = %q, IsRetryable = %v, want %q, %v", tc.err, got, IsRetryable(tc.err), tc.class, tc.retryable)
+		}
+	}
+
+	if GetRetryAfter(fmt.Errorf("call: %w", throttled)) != 2*time.Second || !IsThrottled(throttled) {
+		t.Error("throttled errors should carry their delay")
+	}
+	if !IsTemporary(context.DeadlineExceeded) || !IsPermanent(context.Canceled) {
+		t.Error("IsTemporary() or IsPermanent() of context errors")
+	}
+	if def, _ := Lookup("test.upstream_unavailable"); def.Class != TemporaryClass {
+		t.Errorf("catalog class = %q", def.Class)
+	}
+}
@@ -1,44 +1,134 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 )
 
 // New instantiates a new instance of error object
 func New(code string, severity Severity, description ...interface{}) *Error {
-	return &Error{
+	return newError(nil, code, severity, description)
+}
+
+// Wrap instantiates a new error object caused by err. A nil err yields the
+// same error as New. The stack is only captured when the cause does not
+// already carry one.
+func Wrap(err error, code string, severity Severity, description ...interface{}) *Error {
+	return newError(err, code, severity, description)
+}
+
+// newError must be called directly by the exported constructors for the
+// captured stack to start at their caller
+func newError(cause error, code string, severity Severity, description []interface{}) *Error {
+	e := &Error{
 		Code:        code,
 		Severity:    severity,
 		Description: description,
+		Cause:       cause,
+	}
+	if captureEnabled(severity) && len(e.StackTrace()) == 0 {
+		e.stack = callers()
+	}
+	// A wrapped error of the kit fired the hooks its severity reached
+	// already, the wrapper only fires the hooks of the severities above
+	fired := -1
+	if Is(cause) {
+		fired = chainSeverity(cause).Rank()
+	}
+	if severity.Rank() > fired {
+		fireHooks(context.Background(), CreatedEvent, e, severity, fired)
 	}
+	return e
 }
 
-// Error returns the error description
+// chainSeverity returns the highest severity of the error objects in the
+// chain of err
+func chainSeverity(err error) Severity {
+	highest := GetSeverity(err)
+	for e := err; e != nil; e = Unwrap(e) {
+		if obj, ok := e.(*Error); ok && obj != nil {
+			highest = MaxSeverity(highest, obj.Severity)
+		}
+	}
+	return highest
+}
+
+// Error returns the error description followed by the one of its cause
 func (e *Error) Error() string {
-	return fmt.Sprint(e.Description...)
+	msg := fmt.Sprint(e.Description...)
+	if e.Cause == nil {
+		return msg
+	}
+	if msg == "" {
+		return e.Cause.Error()
+	}
+	return msg + ": " + e.Cause.Error()
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is an error object with the same non empty code,
+// so that errors.Is matches by code through any wrapping
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t == nil {
+		return false
+	}
+	return e.Code != "" && e.Code == t.Code
 }
 
-// GetCode returns the error code
+// GetCode returns the code of the first error object in the chain
 func GetCode(err error) string {
-	if obj := err.(*Error); obj != nil && obj.Code != " " {
+	var obj *Error
+	if stderrors.As(err, &obj) && obj != nil {
 		return obj.Code
 	}
 	return ""
 }
 
-// GetSeverity returns the severity level of the error
+// GetSeverity returns the severity level of the first error object in the
+// chain, or the highest severity of the members of a Multi
 func GetSeverity(err error) Severity {
-	if obj := err.(*Error); obj != nil {
+	for e := err; e != nil; e = Unwrap(e) {
+		switch obj := e.(type) {
+		case *Error:
+			if obj != nil {
+				return obj.Severity
+			}
+		case *Multi:
+			return obj.Severity()
+		}
+	}
+
+	// Fall back to errors.As for chains joining several errors
+	var obj *Error
+	if stderrors.As(err, &obj) && obj != nil {
 		return obj.Severity
 	}
 	return NoneSeverity
 }
 
-// Is returns is the error object is valid
+// Is returns whether the error chain holds an error object
 func Is(err error) bool {
-	if err != nil {
-		_, ok := err.(*Error)
-		return ok
-	}
-	return false
+	var obj *Error
+	return stderrors.As(err, &obj)
+}
+
+// HasCode returns whether the error chain holds an error object with the code
+func HasCode(err error, code string) bool {
+	return code != "" && stderrors.Is(err, &Error{Code: code})
+}
+
+// As finds the first error in the chain that matches target, see the standard errors.As
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, see the standard errors.Unwrap
+func Unwrap(err error) error {
+	return stderrors.Unwrap(err)
 }
@@ -0,0 +1,55 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestWrapChain(t *testing.T) {
+	err := Wrap(io.EOF, "store.read_failed", Critical, "Failed to read record")
+	wrapped := fmt.Errorf("handling request: %w", err)
+
+	if got := wrapped.Error(); got != "handling request: Failed to read record: EOF" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !stderrors.Is(wrapped, io.EOF) {
+		t.Error("errors.Is() should find the cause")
+	}
+	if got := GetCode(wrapped); got != "store.read_failed" {
+		t.Errorf("GetCode() = %q", got)
+	}
+	if got := GetSeverity(wrapped); got != Critical {
+		t.Errorf("GetSeverity() = %q", got)
+	}
+	if !Is(wrapped) {
+		t.Error("Is() should find the error object in the chain")
+	}
+
+	var obj *Error
+	if !As(wrapped, &obj) || obj != err {
+		t.Errorf("As() = %v", obj)
+	}
+}
+
+func TestMatchByCode(t *testing.T) {
+	sentinel := New("cache.key_not_exist", Alert, "Key does not exist")
+	other := New("cache.key_not_exist", Alert, "Key user/1 does not exist")
+	wrapped := fmt.Errorf("lookup: %w", other)
+
+	if !stderrors.Is(wrapped, sentinel) || !HasCode(wrapped, "cache.key_not_exist") {
+		t.Error("errors with the same code should match")
+	}
+	if stderrors.Is(wrapped, New("cache.miss", Alert)) || stderrors.Is(New("", Alert), New("", Alert)) {
+		t.Error("errors with a different or empty code should not match")
+	}
+}
+
+func TestForeignErrors(t *testing.T) {
+	for _, err := range []error{nil, io.EOF, fmt.Errorf("wrapped: %w", io.EOF)} {
+		if GetCode(err) != "" || GetSeverity(err) != NoneSeverity || Is(err) {
+			t.Errorf("foreign error %v should have no code or severity", err)
+		}
+	}
+}
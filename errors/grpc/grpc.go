@@ -0,0 +1,274 @@
+// Package grpc converts errors.Error values to and from gRPC statuses so that
+// they keep their code and severity across service boundaries
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// Domain is the ErrorInfo domain of statuses built from errors.Error
+	Domain = "gokit"
+	// SeverityKey is the ErrorInfo metadata key holding the severity, it
+	// takes precedence over an error metadata key of the same name
+	SeverityKey = "severity"
+	// LanguageKey is the metadata key of incoming calls listing the languages
+	// of the public messages
+	LanguageKey = "accept-language"
+	// ClassKey is the ErrorInfo metadata key holding the classification, it
+	// takes precedence over an error metadata key of the same name
+	ClassKey = "class"
+)
+
+// CodeTable maps errors to gRPC codes. Codes take precedence over classes,
+// then severities, and Default applies to errors matching none.
+type CodeTable struct {
+	Codes      map[string]codes.Code          `json:"codes,omitempty" yaml:"codes,omitempty"`
+	Classes    map[errors.Class]codes.Code    `json:"classes,omitempty" yaml:"classes,omitempty"`
+	Severities map[errors.Severity]codes.Code `json:"severities,omitempty" yaml:"severities,omitempty"`
+	Default    codes.Code                     `json:"default,omitempty" yaml:"default,omitempty"`
+	// Metadata lists the error metadata keys carried in the ErrorInfo detail,
+	// values are sent as strings and no metadata is sent when empty
+	Metadata []string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// DefaultCodeTable is used by the package level functions
+var DefaultCodeTable = CodeTable{
+	Classes: map[errors.Class]codes.Code{
+		errors.ThrottledClass: codes.ResourceExhausted,
+		errors.TemporaryClass: codes.Unavailable,
+	},
+	Severities: map[errors.Severity]codes.Code{
+		errors.NoneSeverity: codes.Internal,
+		errors.Warn:         codes.InvalidArgument,
+		errors.Alert:        codes.Internal,
+		errors.Critical:     codes.Internal,
+		errors.Emergency:    codes.Unavailable,
+		errors.Fatal:        codes.Internal,
+	},
+	Default: codes.Internal,
+}
+
+// Code returns the gRPC code of err
+func (t CodeTable) Code(err error) codes.Code {
+	if errors.Is(err) {
+		if code, ok := t.Codes[errors.GetCode(err)]; ok {
+			return code
+		}
+		if code, ok := t.Classes[errors.GetClass(err)]; ok {
+			return code
+		}
+		if code, ok := t.Severities[errors.GetSeverity(err)]; ok {
+			return code
+		}
+	}
+	if t.Default != codes.OK {
+		return t.Default
+	}
+	return codes.Internal
+}
+
+// Status converts err to a gRPC status. Errors from the kit carry their code
+// and severity in an ErrorInfo detail and their public message in the
+// languages given, statuses are returned unchanged. Other errors get the
+// Default code with its name as message.
+func (t CodeTable) Status(err error, languages ...string) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		// Foreign errors may hold queries or paths, only the code is sent
+		code := t.Code(err)
+		return status.New(code, code.String())
+	}
+
+	code := t.Code(err)
+	message := errors.PublicMessage(err, languages...)
+	if message == "" {
+		// The internal description is never sent
+		message = code.String()
+	}
+	st := status.New(code, message)
+	info := &errdetails.ErrorInfo{
+		Reason:   errors.GetCode(err),
+		Domain:   Domain,
+		Metadata: map[string]string{},
+	}
+	for key, value := range errors.FilterMetadata(err, t.Metadata) {
+		info.Metadata[key] = fmt.Sprint(value)
+	}
+	info.Metadata[SeverityKey] = string(errors.GetSeverity(err))
+	if class := errors.GetClass(err); class != errors.UnclassifiedClass {
+		info.Metadata[ClassKey] = string(class)
+	}
+
+	details := []protoadapt.MessageV1{info}
+	if delay := errors.GetRetryAfter(err); delay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	}
+	if detailed, derr := st.WithDetails(details...); derr == nil {
+		st = detailed
+	}
+	return st
+}
+
+// UnaryServerInterceptor reports and converts the errors returned by unary
+// handlers
+func (t CodeTable) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err != nil {
+			errors.Report(ctx, err)
+			return res, t.Status(err, acceptLanguages(ctx)...).Err()
+		}
+		return res, nil
+	}
+}
+
+// StreamServerInterceptor reports and converts the errors returned by
+// stream handlers
+func (t CodeTable) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			errors.Report(ss.Context(), err)
+			return t.Status(err, acceptLanguages(ss.Context())...).Err()
+		}
+		return nil
+	}
+}
+
+// ToStatus converts err to a gRPC status using DefaultCodeTable
+func ToStatus(err error, languages ...string) *status.Status {
+	return DefaultCodeTable.Status(err, languages...)
+}
+
+// UnaryServerInterceptor converts handler errors using DefaultCodeTable
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return DefaultCodeTable.UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor converts handler errors using DefaultCodeTable
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return DefaultCodeTable.StreamServerInterceptor()
+}
+
+// FromStatus converts a gRPC status back to an error object, nil for an OK
+// status. Statuses without ErrorInfo from the kit get an empty code, and a
+// severity and a classification derived from the gRPC code. Decoding does not
+// fire the creation hooks.
+func FromStatus(st *status.Status) *errors.Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	var err *errors.Error
+	var delay time.Duration
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.GetDomain() != Domain || err != nil {
+				continue
+			}
+			fields := detail.GetMetadata()
+			err = decoded(detail.GetReason(), errors.Severity(fields[SeverityKey]), st.Message()).WithPublic(st.Message())
+			for key, value := range fields {
+				if key != SeverityKey && key != ClassKey {
+					err = err.With(key, value)
+				}
+			}
+			err.Class = errors.Class(fields[ClassKey])
+		case *errdetails.RetryInfo:
+			delay = detail.GetRetryDelay().AsDuration()
+		}
+	}
+	if err == nil {
+		err = decoded("", severityOf(st.Code()), st.Message())
+		err.Class = classOf(st.Code())
+	}
+	err.RetryAfter = delay
+	return err
+}
+
+// decoded builds the error object of a status without firing the creation
+// hooks, which the service returning it fired already
+func decoded(code string, severity errors.Severity, message string) *errors.Error {
+	return &errors.Error{Code: code, Severity: severity, Description: []interface{}{message}}
+}
+
+// FromError converts an error returned by a gRPC call back to an error
+// object. Errors which are not statuses are returned unchanged.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// UnaryClientInterceptor converts the errors of unary calls back to error objects
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// acceptLanguages returns the languages of the accept-language metadata of
+// an incoming call
+func acceptLanguages(ctx context.Context) []string {
+	var languages []string
+	for _, value := range metadata.ValueFromIncomingContext(ctx, LanguageKey) {
+		for _, language := range strings.Split(value, ",") {
+			if language = strings.TrimSpace(strings.SplitN(language, ";", 2)[0]); language != "" {
+				languages = append(languages, language)
+			}
+		}
+	}
+	return languages
+}
+
+// classOf derives a classification for statuses not built from errors.Error
+func classOf(code codes.Code) errors.Class {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return errors.TemporaryClass
+	case codes.ResourceExhausted:
+		return errors.ThrottledClass
+	case codes.Aborted:
+		return errors.RetryableClass
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented:
+		return errors.PermanentClass
+	}
+	return errors.UnclassifiedClass
+}
+
+// severityOf derives a severity for statuses not built from errors.Error
+func severityOf(code codes.Code) errors.Severity {
+	switch code {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.Canceled:
+		return errors.Warn
+	case codes.Unavailable:
+		return errors.Critical
+	default:
+		return errors.Alert
+	}
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoundTrip(t *testing.T) {
+	table := CodeTable{
+		Codes:      map[string]codes.Code{"user.not_found": codes.NotFound},
+		Severities: DefaultCodeTable.Severities,
+		Metadata:   []string{"user_id", "severity"},
+	}
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		err := errors.New("user.not_found", errors.Warn, "User 42 not found in shard db-7").WithPublic("User 42 not found")
+		return nil, err.With("user_id", 42).With("severity", "ignored").With("shard", "db-7")
+	}
+	_, serverErr := table.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if status.Code(serverErr) != codes.NotFound {
+		t.Fatalf("server error code = %v", status.Code(serverErr))
+	}
+
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		// Statuses travel through the proto encoding of the wire
+		return status.FromProto(status.Convert(serverErr).Proto()).Err()
+	}
+	err := UnaryClientInterceptor()(context.Background(), "/users.Users/Get", nil, nil, nil, invoker)
+	if errors.GetCode(err) != "user.not_found" || errors.GetSeverity(err) != errors.Warn || err.Error() != "User 42 not found" {
+		t.Errorf("client error = %v (%q, %q)", err, errors.GetCode(err), errors.GetSeverity(err))
+	}
+	if metadata := errors.GetMetadata(err); len(metadata) != 1 || metadata["user_id"] != "42" {
+		t.Errorf("client metadata = %v, want only the exposed keys", metadata)
+	}
+}
+
+func TestPublicMessages(t *testing.T) {
+	errors.RegisterMessages("de", map[string]string{"order.locked": "Bestellung {order_id} ist gesperrt"})
+	err := errors.New("order.locked", errors.Warn, "Row lock on orders.id=7").With("order_id", 7)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(LanguageKey, "de-AT, en;q=0.8"))
+	handler := func(context.Context, interface{}) (interface{}, error) { return nil, err }
+	_, serverErr := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	if got := status.Convert(serverErr).Message(); got != "Bestellung 7 ist gesperrt" {
+		t.Errorf("localised message = %q", got)
+	}
+	if got := ToStatus(err).Message(); got != codes.InvalidArgument.String() {
+		t.Errorf("message without template = %q, want the code name", got)
+	}
+}
+
+func TestForeignErrors(t *testing.T) {
+	if got := ToStatus(io.EOF); got.Code() != codes.Internal {
+		t.Errorf("ToStatus(io.EOF) code = %v, want the default", got.Code())
+	}
+	leak := fmt.Errorf("query users: pq: relation \"users\" does not exist at /srv/app/db.go:42")
+	if got := ToStatus(leak); got.Code() != codes.Internal || got.Message() != codes.Internal.String() {
+		t.Errorf("ToStatus() of a plain error = %v %q, want the default code and its name", got.Code(), got.Message())
+	}
+	table := CodeTable{Default: codes.Unavailable}
+	if got := table.Status(leak); got.Code() != codes.Unavailable || got.Message() != codes.Unavailable.String() {
+		t.Errorf("Status() of a plain error = %v %q, want the table default", got.Code(), got.Message())
+	}
+	if got := ToStatus(status.Error(codes.NotFound, "missing")); got.Code() != codes.NotFound {
+		t.Errorf("ToStatus() of a status code = %v", got.Code())
+	}
+	if got := ToStatus(errors.New("", errors.Emergency, "down")); got.Code() != codes.Unavailable {
+		t.Errorf("ToStatus() of an emergency = %v", got.Code())
+	}
+
+	err := FromError(status.Error(codes.PermissionDenied, "denied"))
+	if errors.GetCode(err) != "" || errors.GetSeverity(err) != errors.Warn || err.Error() != "denied" {
+		t.Errorf("FromError() = %v (%q)", err, errors.GetSeverity(err))
+	}
+	if FromError(io.EOF) != io.EOF || FromError(nil) != nil {
+		t.Error("FromError() should pass through non status errors")
+	}
+}
+
+func TestClassRoundTrip(t *testing.T) {
+	err := FromStatus(status.Convert(ToStatus(errors.New("api.rate_limited", errors.Warn, "Slow down").WithRetryAfter(3 * time.Second)).Err()))
+	if !errors.IsThrottled(err) || errors.GetRetryAfter(err) != 3*time.Second {
+		t.Errorf("FromStatus() = %v, class %q, retry after %v", err, errors.GetClass(err), errors.GetRetryAfter(err))
+	}
+	if code := ToStatus(errors.New("", errors.Warn, "Slow down").WithRetryAfter(time.Second)).Code(); code != codes.ResourceExhausted {
+		t.Errorf("throttled code = %v", code)
+	}
+	if err := FromError(status.Error(codes.Unavailable, "down")); !errors.IsTemporary(err) {
+		t.Errorf("FromError() of Unavailable class = %q", errors.GetClass(err))
+	}
+}
+
+func TestDecodingFiresNoHooks(t *testing.T) {
+	var fired []string
+	remove := errors.RegisterHook(errors.NoneSeverity, func(_ context.Context, event errors.HookEvent, err error) {
+		fired = append(fired, string(event)+" "+errors.GetCode(err))
+	})
+	defer remove()
+
+	st := ToStatus(errors.New("db.down", errors.Emergency, "Database down"))
+	fired = nil
+	if err := FromStatus(st); errors.GetSeverity(err) != errors.Emergency {
+		t.Errorf("FromStatus() severity = %q", errors.GetSeverity(err))
+	}
+	if err := FromError(status.Error(codes.Unavailable, "connection refused")); errors.GetSeverity(err) != errors.Critical || !errors.IsTemporary(err) {
+		t.Errorf("FromError() of a foreign unavailable status = %v (%q)", err, errors.GetSeverity(err))
+	}
+	if len(fired) != 0 {
+		t.Errorf("decoding fired %v, the remote service fired them already", fired)
+	}
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// CreatedEvent is passed to hooks when an error is created
+	CreatedEvent HookEvent = "created"
+	// ReportedEvent is passed to hooks when an error is reported
+	ReportedEvent HookEvent = "reported"
+)
+
+// HookEvent tells hooks why they are invoked
+type HookEvent string
+
+// Hook is invoked with errors of at least the severity it was registered
+// for. Hooks run synchronously on the goroutine creating or reporting the
+// error, slow work such as paging should be handed off.
+type Hook func(ctx context.Context, event HookEvent, err error)
+
+type registeredHook struct {
+	id    uint64
+	event HookEvent
+	min   Severity
+	hook  Hook
+}
+
+// fires reports whether the hook runs for event, a hook without event runs
+// for all of them
+func (h registeredHook) fires(event HookEvent) bool {
+	return h.event == "" || h.event == event
+}
+
+var hooks = struct {
+	sync.RWMutex
+	next    uint64
+	entries []registeredHook
+	// created and reported count the hooks running for each event
+	created  int32
+	reported int32
+}{}
+
+// RegisterHook installs a hook fired for errors of severity min or higher
+// and returns a function removing it
+func RegisterHook(min Severity, hook Hook) func() {
+	return RegisterEventHook("", min, hook)
+}
+
+// RegisterEventHook installs a hook fired for errors of severity min or
+// higher with event only, and returns a function removing it. Hooks which
+// only count reported errors keep the creation of errors free.
+func RegisterEventHook(event HookEvent, min Severity, hook Hook) func() {
+	hooks.Lock()
+	defer hooks.Unlock()
+	hooks.next++
+	id := hooks.next
+	hooks.entries = append(hooks.entries, registeredHook{id: id, event: event, min: min, hook: hook})
+	countHooks()
+
+	return func() {
+		hooks.Lock()
+		defer hooks.Unlock()
+		for i, entry := range hooks.entries {
+			if entry.id == id {
+				hooks.entries = append(hooks.entries[:i:i], hooks.entries[i+1:]...)
+				break
+			}
+		}
+		countHooks()
+	}
+}
+
+// countHooks updates the number of hooks of each event, hooks must be locked
+func countHooks() {
+	var created, reported int32
+	for _, entry := range hooks.entries {
+		if entry.fires(CreatedEvent) {
+			created++
+		}
+		if entry.fires(ReportedEvent) {
+			reported++
+		}
+	}
+	atomic.StoreInt32(&hooks.created, created)
+	atomic.StoreInt32(&hooks.reported, reported)
+}
+
+// fireHooks runs the hooks whose minimum severity is reached by severity and
+// ranks above fired, the rank of the hooks run already for the same failure
+// or -1
+func fireHooks(ctx context.Context, event HookEvent, err error, severity Severity, fired int) {
+	// Keep error creation cheap when no hook is registered for it
+	count := &hooks.created
+	if event == ReportedEvent {
+		count = &hooks.reported
+	}
+	if atomic.LoadInt32(count) == 0 {
+		return
+	}
+
+	hooks.RLock()
+	matched := make([]Hook, 0, len(hooks.entries))
+	for _, entry := range hooks.entries {
+		if entry.fires(event) && severity.AtLeast(entry.min) && entry.min.Rank() > fired {
+			matched = append(matched, entry.hook)
+		}
+	}
+	hooks.RUnlock()
+
+	for _, hook := range matched {
+		hook(ctx, event, err)
+	}
+}
@@ -0,0 +1,68 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSeverityOrder(t *testing.T) {
+	order := []Severity{NoneSeverity, Warn, Critical, Alert, Emergency, Fatal}
+	for i := 1; i < len(order); i++ {
+		if CompareSeverity(order[i-1], order[i]) != -1 || !order[i].AtLeast(order[i-1]) || order[i-1].AtLeast(order[i]) {
+			t.Errorf("%s should rank below %s", order[i-1], order[i])
+		}
+	}
+	if MaxSeverity(Warn, Fatal, Alert) != Fatal || MaxSeverity() != NoneSeverity || Severity("unknown").Rank() != 0 {
+		t.Error("MaxSeverity() or Rank() of unknown severities")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var got []string
+	remove := RegisterHook(Critical, func(_ context.Context, event HookEvent, err error) {
+		got = append(got, fmt.Sprintf("%s %s %s", event, GetSeverity(err), err))
+	})
+
+	New("", Warn, "ignored")
+	root := New("", Emergency, "created")
+	Wrap(fmt.Errorf("query: %w", root), "", Fatal, "wrapped again")
+	Wrap(context.DeadlineExceeded, "", Alert, "timed out")
+	Wrap(New("", Warn, "degraded"), "", Critical, "outage")
+	Wrap(Wrap(New("", Alert, "down"), "", Warn, "retrying"), "", Critical, "gave up")
+	Report(context.Background(), fmt.Errorf("wrapped: %w", New("", Warn, "low")))
+	Report(context.Background(), Combine(New("", Warn, "a"), New("", Alert, "b")))
+	Report(context.Background(), nil)
+	remove()
+	New("", Fatal, "after removal")
+
+	want := []string{
+		"created emergency created",
+		"created alert timed out: context deadline exceeded",
+		"created critical outage: degraded",
+		"created alert down",
+		"created alert b",
+		"reported alert 2 errors occurred:\n\t* a\n\t* b",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("hook calls = %q, want %q", got, want)
+	}
+}
+
+func TestEventHooks(t *testing.T) {
+	var got []string
+	remove := RegisterEventHook(ReportedEvent, Warn, func(_ context.Context, event HookEvent, err error) {
+		got = append(got, fmt.Sprintf("%s %s", event, err))
+	})
+	defer remove()
+	if atomic.LoadInt32(&hooks.created) != 0 {
+		t.Error("a hook of reported errors makes the creation of errors fire hooks")
+	}
+
+	err := New("", Critical, "failed")
+	Report(context.Background(), err)
+	if want := []string{"reported failed"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("hook calls = %q, want %q", got, want)
+	}
+}
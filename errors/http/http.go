@@ -0,0 +1,243 @@
+// Package http renders errors.Error values as RFC 7807 problem details and
+// decodes them back on the client side
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+	"github.com/kumarabd/gokit/tracing/otel"
+)
+
+// ContentType is the media type of problem details bodies
+const ContentType = "application/problem+json"
+
+// maxProblemBytes bounds the body read by Decode
+const maxProblemBytes = 1 << 20
+
+// Problem is the RFC 7807 body written for an error, extended with the error
+// code, severity and trace id
+type Problem struct {
+	Type     string                 `json:"type,omitempty"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     string                 `json:"code,omitempty"`
+	Severity errors.Severity        `json:"severity,omitempty"`
+	TraceID  string                 `json:"trace_id,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Class    errors.Class           `json:"class,omitempty"`
+}
+
+// StatusTable maps errors to HTTP status codes. Codes take precedence over
+// classes, then severities, and Default applies to errors matching none.
+type StatusTable struct {
+	Codes      map[string]int          `json:"codes,omitempty" yaml:"codes,omitempty"`
+	Classes    map[errors.Class]int    `json:"classes,omitempty" yaml:"classes,omitempty"`
+	Severities map[errors.Severity]int `json:"severities,omitempty" yaml:"severities,omitempty"`
+	Default    int                     `json:"default,omitempty" yaml:"default,omitempty"`
+	// Metadata lists the error metadata keys exposed in response bodies,
+	// no metadata is exposed when empty
+	Metadata []string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// DefaultStatusTable is used by the package level functions
+var DefaultStatusTable = StatusTable{
+	Classes: map[errors.Class]int{
+		errors.ThrottledClass: http.StatusTooManyRequests,
+	},
+	Severities: map[errors.Severity]int{
+		errors.NoneSeverity: http.StatusInternalServerError,
+		errors.Warn:         http.StatusBadRequest,
+		errors.Alert:        http.StatusInternalServerError,
+		errors.Critical:     http.StatusInternalServerError,
+		errors.Emergency:    http.StatusServiceUnavailable,
+		errors.Fatal:        http.StatusInternalServerError,
+	},
+	Default: http.StatusInternalServerError,
+}
+
+// Status returns the HTTP status code of err
+func (t StatusTable) Status(err error) int {
+	if errors.Is(err) {
+		if status, ok := t.Codes[errors.GetCode(err)]; ok {
+			return status
+		}
+		if status, ok := t.Classes[errors.GetClass(err)]; ok {
+			return status
+		}
+		if status, ok := t.Severities[errors.GetSeverity(err)]; ok {
+			return status
+		}
+	}
+	if t.Default != 0 {
+		return t.Default
+	}
+	return http.StatusInternalServerError
+}
+
+// Problem builds the problem details of err for the request r. The detail is
+// the public message of err in the languages accepted by the request, the
+// internal description is never exposed.
+func (t StatusTable) Problem(r *http.Request, err error) Problem {
+	status := t.Status(err)
+	var languages []string
+	if r != nil {
+		languages = acceptLanguages(r.Header.Get("Accept-Language"))
+	}
+	problem := Problem{
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   errors.PublicMessage(err, languages...),
+		Code:     errors.GetCode(err),
+		Severity: errors.GetSeverity(err),
+		Metadata: errors.FilterMetadata(err, t.Metadata),
+		Class:    errors.GetClass(err),
+	}
+	if r != nil {
+		problem.Instance = r.URL.RequestURI()
+		problem.TraceID = otel.TraceID(r.Context())
+	}
+	return problem
+}
+
+// Write renders err as a problem+json response and reports it with
+// errors.Report
+func (t StatusTable) Write(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	errors.Report(ctx, err)
+
+	problem := t.Problem(r, err)
+	w.Header().Set("Content-Type", ContentType)
+	if delay := errors.GetRetryAfter(err); delay > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	}
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// Status returns the HTTP status code of err using DefaultStatusTable
+func Status(err error) int {
+	return DefaultStatusTable.Status(err)
+}
+
+// Write renders err as a problem+json response using DefaultStatusTable
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	DefaultStatusTable.Write(w, r, err)
+}
+
+// Decode returns the error carried by a response, nil for successful
+// responses. Problem details bodies are turned back into *errors.Error,
+// other failed responses yield an error with the status text. The error is
+// classified from the status and the Retry-After header when the body does
+// not carry a class.
+func Decode(res *http.Response) error {
+	if res.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxProblemBytes))
+	if err != nil {
+		return err
+	}
+	return DecodeResponse(res.StatusCode, res.Header, body)
+}
+
+// DecodeBody is Decode for a response whose body has already been read
+func DecodeBody(status int, contentType string, body []byte) error {
+	return DecodeResponse(status, http.Header{"Content-Type": []string{contentType}}, body)
+}
+
+// DecodeResponse is Decode for a response whose body has already been read
+func DecodeResponse(status int, header http.Header, body []byte) error {
+	if status < http.StatusBadRequest {
+		return nil
+	}
+
+	problem := Problem{Status: status, Title: http.StatusText(status)}
+	if mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType == ContentType {
+		_ = json.Unmarshal(body, &problem)
+	}
+	if problem.Severity == "" {
+		problem.Severity = errors.Alert
+		if status < http.StatusInternalServerError {
+			problem.Severity = errors.Warn
+		}
+	}
+	detail := problem.Detail
+	if detail == "" {
+		detail = problem.Title
+	}
+	// The service returning the problem fired the creation hooks already
+	decoded := (&errors.Error{Code: problem.Code, Severity: problem.Severity, Description: []interface{}{detail}}).WithPublic(problem.Detail)
+	for key, value := range problem.Metadata {
+		decoded = decoded.With(key, value)
+	}
+
+	if problem.Class == errors.UnclassifiedClass {
+		problem.Class = classOf(status)
+	}
+	decoded.Class = problem.Class
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		decoded.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return decoded
+}
+
+// classOf classifies the failed responses of errors outside the kit
+func classOf(status int) errors.Class {
+	switch status {
+	case http.StatusTooManyRequests:
+		return errors.ThrottledClass
+	case http.StatusRequestTimeout, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errors.TemporaryClass
+	}
+	if status < http.StatusInternalServerError {
+		return errors.PermanentClass
+	}
+	return errors.UnclassifiedClass
+}
+
+// acceptLanguages returns the languages of an Accept-Language header in
+// order of preference
+func acceptLanguages(header string) []string {
+	type weighted struct {
+		language string
+		q        float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" || fields[0] == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		tags = append(tags, weighted{language: fields[0], q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	languages := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		languages = append(languages, tag.language)
+	}
+	return languages
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestProblemRoundTrip(t *testing.T) {
+	table := StatusTable{
+		Codes:      map[string]int{"user.not_found": http.StatusNotFound},
+		Severities: DefaultStatusTable.Severities,
+		Metadata:   []string{"user_id"},
+	}
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	errors.RegisterMessages("en", map[string]string{"user.not_found": "User {user_id} was not found"})
+	errors.RegisterMessages("fr", map[string]string{"user.not_found": "Utilisateur {user_id} introuvable"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/users/42?expand=true", nil).WithContext(ctx)
+	r.Header.Set("Accept-Language", "de;q=0.2, fr-CA, en;q=0.5")
+	table.Write(w, r, errors.New("user.not_found", errors.Warn, "User 42 not found in shard db-7").With("user_id", "42").With("shard", "db-7"))
+
+	res := w.Result()
+	if res.StatusCode != http.StatusNotFound || res.Header.Get("Content-Type") != ContentType {
+		t.Fatalf("status = %d, content type = %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	problem := table.Problem(r, errors.New("user.not_found", errors.Warn, "User 42 not found"))
+	if problem.Title != "Not Found" || problem.Instance != "/users/42?expand=true" || problem.TraceID != span.SpanContext().TraceID().String() {
+		t.Errorf("Problem() = %+v", problem)
+	}
+
+	err := Decode(res)
+	if errors.GetCode(err) != "user.not_found" || errors.GetSeverity(err) != errors.Warn || err.Error() != "Utilisateur 42 introuvable" {
+		t.Errorf("Decode() = %v (%q, %q)", err, errors.GetCode(err), errors.GetSeverity(err))
+	}
+	if metadata := errors.GetMetadata(err); len(metadata) != 1 || metadata["user_id"] != "42" {
+		t.Errorf("decoded metadata = %v, want only the exposed keys", metadata)
+	}
+}
+
+func TestProblemHidesInternalDetail(t *testing.T) {
+	problem := DefaultStatusTable.Problem(httptest.NewRequest(http.MethodGet, "/", nil), errors.New("db.query_failed", errors.Critical, "SELECT * FROM users failed on db-7.internal"))
+	if problem.Detail != "" || problem.Title != "Internal Server Error" {
+		t.Errorf("Problem() = %+v, want no detail", problem)
+	}
+	problem = DefaultStatusTable.Problem(nil, errors.New("", errors.Warn, "internal").WithPublic("Try again later"))
+	if problem.Detail != "Try again later" {
+		t.Errorf("Problem() detail = %q", problem.Detail)
+	}
+}
+
+func TestStatusMapping(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{errors.New("", errors.Warn, "bad input"), http.StatusBadRequest},
+		{errors.New("", errors.Emergency, "down"), http.StatusServiceUnavailable},
+		{errors.New("", "custom", "unknown severity"), http.StatusInternalServerError},
+		{context.Canceled, http.StatusInternalServerError},
+	} {
+		if got := Status(tc.err); got != tc.want {
+			t.Errorf("Status(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestDecodePlainResponses(t *testing.T) {
+	if err := DecodeBody(http.StatusOK, ContentType, nil); err != nil {
+		t.Errorf("DecodeBody() of a success = %v", err)
+	}
+	err := DecodeBody(http.StatusBadGateway, "text/html", []byte("<html>"))
+	if err == nil || err.Error() != "Bad Gateway" || errors.GetSeverity(err) != errors.Alert {
+		t.Errorf("DecodeBody() = %v", err)
+	}
+}
+
+func TestThrottledRoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("api.rate_limited", errors.Warn, "Slow down").WithRetryAfter(1500*time.Millisecond))
+
+	res := w.Result()
+	if res.StatusCode != http.StatusTooManyRequests || res.Header.Get("Retry-After") != "2" {
+		t.Fatalf("status = %d, Retry-After = %q", res.StatusCode, res.Header.Get("Retry-After"))
+	}
+	err := Decode(res)
+	if !errors.IsThrottled(err) || errors.GetRetryAfter(err) != 2*time.Second {
+		t.Errorf("Decode() = %v, class %q, retry after %v", err, errors.GetClass(err), errors.GetRetryAfter(err))
+	}
+
+	if err := DecodeBody(http.StatusServiceUnavailable, "text/plain", nil); !errors.IsTemporary(err) {
+		t.Errorf("DecodeBody() of a 503 class = %q", errors.GetClass(err))
+	}
+	if err := DecodeBody(http.StatusNotFound, "text/plain", nil); !errors.IsPermanent(err) {
+		t.Errorf("DecodeBody() of a 404 class = %q", errors.GetClass(err))
+	}
+}
+
+func TestDecodingFiresNoHooks(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("db.down", errors.Emergency, "Database down"))
+	res := w.Result()
+
+	var fired []string
+	remove := errors.RegisterHook(errors.NoneSeverity, func(_ context.Context, event errors.HookEvent, err error) {
+		fired = append(fired, string(event)+" "+errors.GetCode(err))
+	})
+	defer remove()
+	if err := Decode(res); errors.GetSeverity(err) != errors.Emergency {
+		t.Errorf("Decode() severity = %q", errors.GetSeverity(err))
+	}
+	if len(fired) != 0 {
+		t.Errorf("decoding fired %v, the remote service fired them already", fired)
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+	"github.com/kumarabd/gokit/tracing/otel"
+)
+
+// WebhookOptions configures the webhook hook
+type WebhookOptions struct {
+	URL     string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Timeout time.Duration     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxInFlight bounds the concurrent deliveries, events beyond it are dropped
+	MaxInFlight int `json:"max_in_flight,omitempty" yaml:"max_in_flight,omitempty"`
+}
+
+// WebhookPayload is the JSON body posted for each event
+type WebhookPayload struct {
+	Event    errors.HookEvent       `json:"event"`
+	Time     time.Time              `json:"time"`
+	Code     string                 `json:"code,omitempty"`
+	Severity errors.Severity        `json:"severity"`
+	Message  string                 `json:"message"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	TraceID  string                 `json:"trace_id,omitempty"`
+}
+
+// Webhook returns an errors.Hook posting each event to a webhook, e.g. to page
+// on Emergency errors. Deliveries happen in the background so that the
+// creation or the report of the error is not delayed.
+func Webhook(opts WebhookOptions) errors.Hook {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 16
+	}
+	client := &http.Client{Timeout: opts.Timeout}
+	inFlight := make(chan struct{}, opts.MaxInFlight)
+
+	return func(ctx context.Context, event errors.HookEvent, err error) {
+		body, merr := json.Marshal(WebhookPayload{
+			Event:    event,
+			Time:     time.Now().UTC(),
+			Code:     errors.GetCode(err),
+			Severity: errors.GetSeverity(err),
+			Message:  err.Error(),
+			Metadata: errors.GetMetadata(err),
+			TraceID:  otel.TraceID(ctx),
+		})
+		if merr != nil {
+			return
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			return
+		}
+		go func() {
+			defer func() { <-inFlight }()
+			req, rerr := http.NewRequest(http.MethodPost, opts.URL, bytes.NewReader(body))
+			if rerr != nil {
+				return
+			}
+			req.Header.Set("Content-Type", "application/json")
+			for key, value := range opts.Headers {
+				req.Header.Set(key, value)
+			}
+			res, rerr := client.Do(req)
+			if rerr != nil {
+				return
+			}
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}()
+	}
+}
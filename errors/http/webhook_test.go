@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/errors"
+)
+
+func TestWebhookPagesOnEmergency(t *testing.T) {
+	received := make(chan WebhookPayload, 4)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || r.Header.Get("Authorization") != "Bearer pager" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- payload
+	}))
+	defer endpoint.Close()
+
+	remove := errors.RegisterHook(errors.Emergency, Webhook(WebhookOptions{
+		URL:     endpoint.URL,
+		Headers: map[string]string{"Authorization": "Bearer pager"},
+	}))
+	defer remove()
+
+	errors.Report(context.Background(), errors.New("db.down", errors.Critical, "Replica lagging"))
+	errors.Report(context.Background(), errors.New("db.down", errors.Emergency, "Database down").With("cluster", "eu-1"))
+
+	// The creation and the report of the emergency error are both delivered
+	events := map[errors.HookEvent]WebhookPayload{}
+	for len(events) < 2 {
+		select {
+		case payload := <-received:
+			if payload.Code != "db.down" || payload.Severity != errors.Emergency || payload.Message != "Database down" {
+				t.Errorf("payload = %+v", payload)
+			}
+			events[payload.Event] = payload
+		case <-time.After(5 * time.Second):
+			t.Fatalf("deliveries = %v, want created and reported events", events)
+		}
+	}
+	if events[errors.ReportedEvent].Metadata["cluster"] != "eu-1" {
+		t.Errorf("reported payload = %+v", events[errors.ReportedEvent])
+	}
+	select {
+	case payload := <-received:
+		t.Errorf("unexpected delivery %+v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
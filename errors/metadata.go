@@ -0,0 +1,67 @@
+package errors
+
+import "sort"
+
+// With returns a copy of the error carrying the key/value pair in its
+// metadata, the receiver is left untouched so that package level errors can
+// be decorated safely
+func (e *Error) With(key string, value interface{}) *Error {
+	out := *e
+	out.Metadata = make(map[string]interface{}, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		out.Metadata[k] = v
+	}
+	out.Metadata[key] = value
+	return &out
+}
+
+// GetMetadata returns the metadata of every error object in the chain, the
+// outermost errors taking precedence. It returns nil when there is none.
+func GetMetadata(err error) map[string]interface{} {
+	var chain []*Error
+	for err != nil {
+		if obj, ok := err.(*Error); ok && obj != nil {
+			chain = append(chain, obj)
+		}
+		err = Unwrap(err)
+	}
+
+	var out map[string]interface{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i].Metadata {
+			if out == nil {
+				out = make(map[string]interface{})
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// FilterMetadata returns the metadata of the chain restricted to keys
+func FilterMetadata(err error, keys []string) map[string]interface{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	metadata := GetMetadata(err)
+	var out map[string]interface{}
+	for _, key := range keys {
+		if v, ok := metadata[key]; ok {
+			if out == nil {
+				out = make(map[string]interface{}, len(keys))
+			}
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// metadataKeys returns the keys of metadata in a stable order
+func metadataKeys(metadata map[string]interface{}) []string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
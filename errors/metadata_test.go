@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWithMetadata(t *testing.T) {
+	sentinel := New("order.rejected", Warn, "Order rejected")
+	err := sentinel.With("order_id", "A-1").With("retry_after", 30)
+
+	if sentinel.Metadata != nil {
+		t.Errorf("With() modified the receiver: %v", sentinel.Metadata)
+	}
+	if !HasCode(err, "order.rejected") {
+		t.Error("decorated error lost its code")
+	}
+
+	wrapped := fmt.Errorf("checkout: %w", Wrap(err, "checkout.failed", Alert, "Checkout failed").With("order_id", "A-2"))
+	metadata := GetMetadata(wrapped)
+	if metadata["order_id"] != "A-2" || metadata["retry_after"] != 30 || len(metadata) != 2 {
+		t.Errorf("GetMetadata() = %v", metadata)
+	}
+	if got := FilterMetadata(wrapped, []string{"retry_after", "missing"}); len(got) != 1 || got["retry_after"] != 30 {
+		t.Errorf("FilterMetadata() = %v", got)
+	}
+	if GetMetadata(New("", Warn)) != nil || FilterMetadata(wrapped, nil) != nil {
+		t.Error("errors without metadata should yield nil")
+	}
+
+	if out := fmt.Sprintf("%+v", err); !strings.HasPrefix(out, "Order rejected\norder_id=A-1 retry_after=30") {
+		t.Errorf("%%+v = %q", out)
+	}
+}
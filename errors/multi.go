@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Multi collects the errors of fan-out operations. It is safe for concurrent
+// use and its zero value is ready to use.
+type Multi struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Combine returns a Multi holding the non nil errors, nil when there is none
+func Combine(errs ...error) error {
+	m := &Multi{}
+	for _, err := range errs {
+		m.Append(err)
+	}
+	return m.Err()
+}
+
+// Append adds the non nil errors to the collection
+func (m *Multi) Append(errs ...error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, err := range errs {
+		if err != nil {
+			m.errs = append(m.errs, err)
+		}
+	}
+}
+
+// Err returns the collection as an error, nil when it is empty
+func (m *Multi) Err() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Len returns the number of collected errors
+func (m *Multi) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.errs)
+}
+
+// Errors returns a copy of the collected errors
+func (m *Multi) Errors() []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]error(nil), m.errs...)
+}
+
+// Unwrap returns the collected errors so that errors.Is and errors.As look
+// through every member
+func (m *Multi) Unwrap() []error {
+	return m.Errors()
+}
+
+// Severity returns the highest severity among the members
+func (m *Multi) Severity() Severity {
+	errs := m.Errors()
+	severities := make([]Severity, 0, len(errs))
+	for _, err := range errs {
+		severities = append(severities, GetSeverity(err))
+	}
+	return MaxSeverity(severities...)
+}
+
+// Class returns RetryableClass, or ThrottledClass when a member is
+// throttled, if every member can be retried. Otherwise it returns
+// PermanentClass if a member is permanent, UnclassifiedClass if not.
+func (m *Multi) Class() Class {
+	errs := m.Errors()
+	if len(errs) == 0 {
+		return UnclassifiedClass
+	}
+
+	class, retryable, permanent := RetryableClass, true, false
+	for _, err := range errs {
+		switch member := GetClass(err); member {
+		case ThrottledClass:
+			class = ThrottledClass
+		case RetryableClass, TemporaryClass:
+		default:
+			retryable = false
+			permanent = permanent || member == PermanentClass
+		}
+	}
+	switch {
+	case permanent:
+		return PermanentClass
+	case !retryable:
+		return UnclassifiedClass
+	}
+	return class
+}
+
+// Error lists the messages of the members
+func (m *Multi) Error() string {
+	errs := m.Errors()
+	switch len(errs) {
+	case 0:
+		return "no errors"
+	case 1:
+		return errs[0].Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(errs)))
+	b.WriteString(" errors occurred:")
+	for _, err := range errs {
+		b.WriteString("\n\t* ")
+		b.WriteString(strings.ReplaceAll(err.Error(), "\n", "\n\t  "))
+	}
+	return b.String()
+}
+
+// MarshalJSON renders the collection as the list of the member codes, empty
+// for members without code
+func (m *Multi) MarshalJSON() ([]byte, error) {
+	errs := m.Errors()
+	codes := make([]string, 0, len(errs))
+	for _, err := range errs {
+		codes = append(codes, GetCode(err))
+	}
+	return json.Marshal(codes)
+}
@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMulti(t *testing.T) {
+	notFound := New("cache.key_not_exist", Warn, "Key does not exist")
+	m := &Multi{}
+	if m.Err() != nil || Combine(nil, nil) != nil {
+		t.Fatal("empty collections should yield a nil error")
+	}
+
+	var wg sync.WaitGroup
+	for _, err := range []error{notFound, nil, Wrap(io.EOF, "cache.write_failed", Critical, "Write failed"), fmt.Errorf("timeout")} {
+		wg.Add(1)
+		go func(err error) {
+			defer wg.Done()
+			m.Append(err)
+		}(err)
+	}
+	wg.Wait()
+
+	err := fmt.Errorf("batch: %w", m.Err())
+	if m.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", m.Len())
+	}
+	if GetSeverity(err) != Critical {
+		t.Errorf("GetSeverity() = %q, want the highest member severity", GetSeverity(err))
+	}
+	if !stderrors.Is(err, notFound) || !stderrors.Is(err, io.EOF) || !HasCode(err, "cache.write_failed") {
+		t.Error("errors.Is should match every member")
+	}
+	var target *Error
+	if !As(err, &target) {
+		t.Error("errors.As should find a member")
+	}
+
+	data, jerr := json.Marshal(m)
+	var codes []string
+	if jerr != nil || json.Unmarshal(data, &codes) != nil || len(codes) != 3 {
+		t.Errorf("MarshalJSON() = %s, %v", data, jerr)
+	}
+}
+
+func TestMultiClass(t *testing.T) {
+	throttled := New("api.rate_limited", Warn, "Rate limited").WithRetryAfter(time.Second)
+	plain := New("", Warn, "plain")
+	for _, tc := range []struct {
+		errs []error
+		want Class
+	}{
+		{[]error{errTestUpstream.New(), throttled}, ThrottledClass},
+		{[]error{throttled, errTestUpstream.New()}, ThrottledClass},
+		{[]error{context.Canceled, throttled}, PermanentClass},
+		{[]error{throttled, context.Canceled}, PermanentClass},
+		{[]error{plain, throttled}, UnclassifiedClass},
+		{[]error{throttled, plain}, UnclassifiedClass},
+		{[]error{plain, context.Canceled, throttled}, PermanentClass},
+		{[]error{throttled, context.Canceled, plain}, PermanentClass},
+	} {
+		if got := GetClass(Combine(tc.errs...)); got != tc.want {
+			t.Errorf("Class() of %v = %q, want %q", tc.errs, got, tc.want)
+		}
+	}
+}
+
+func TestMultiFormat(t *testing.T) {
+	if got := Combine(io.EOF).Error(); got != "EOF" {
+		t.Errorf("single member Error() = %q", got)
+	}
+	got := Combine(io.EOF, Combine(io.ErrUnexpectedEOF, io.ErrShortWrite)).Error()
+	want := "2 errors occurred:\n\t* EOF\n\t* 2 errors occurred:\n\t  \t* unexpected EOF\n\t  \t* short write"
+	if got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
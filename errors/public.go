@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language of the messages used when none of the
+// requested languages has a message for a code
+const DefaultLanguage = "en"
+
+var messages = struct {
+	sync.RWMutex
+	templates map[string]map[string]string // language -> code -> template
+}{templates: make(map[string]map[string]string)}
+
+// RegisterMessages adds user-facing message templates for a language, keyed
+// by error code. Templates may reference metadata as {key}, e.g.
+// "Order {order_id} cannot be cancelled".
+func RegisterMessages(language string, templates map[string]string) {
+	language = strings.ToLower(language)
+
+	messages.Lock()
+	defer messages.Unlock()
+	if messages.templates[language] == nil {
+		messages.templates[language] = make(map[string]string, len(templates))
+	}
+	for code, template := range templates {
+		messages.templates[language][code] = template
+	}
+}
+
+// WithPublic returns a copy of the error with a user-facing message, which
+// takes precedence over the registered templates
+func (e *Error) WithPublic(message string) *Error {
+	out := *e
+	out.Public = message
+	return &out
+}
+
+// PublicMessage returns the user-facing message of err. It is the message set
+// with WithPublic on the first error object in the chain carrying one, or the
+// template registered for its code in the first of the languages having one,
+// falling back to DefaultLanguage. It returns an empty string otherwise, and
+// never exposes the internal description.
+func PublicMessage(err error, languages ...string) string {
+	var code string
+	for e := err; e != nil; e = Unwrap(e) {
+		obj, ok := e.(*Error)
+		if !ok || obj == nil {
+			continue
+		}
+		if obj.Public != "" {
+			return obj.Public
+		}
+		if code == "" {
+			code = obj.Code
+		}
+	}
+	if code == "" {
+		return ""
+	}
+
+	template, ok := lookupMessage(code, languages)
+	if !ok {
+		return ""
+	}
+	metadata := GetMetadata(err)
+	if len(metadata) == 0 {
+		return template
+	}
+	pairs := make([]string, 0, 2*len(metadata))
+	for _, key := range metadataKeys(metadata) {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(metadata[key]))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// lookupMessage tries each language, then its base language, e.g. "fr" for
+// "fr-CA", then DefaultLanguage
+func lookupMessage(code string, languages []string) (string, bool) {
+	messages.RLock()
+	defer messages.RUnlock()
+
+	candidates := make([]string, 0, 2*len(languages)+1)
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		candidates = append(candidates, language)
+		if i := strings.IndexByte(language, '-'); i > 0 {
+			candidates = append(candidates, language[:i])
+		}
+	}
+	candidates = append(candidates, DefaultLanguage)
+
+	for _, language := range candidates {
+		if template, ok := messages.templates[language][code]; ok {
+			return template, true
+		}
+	}
+	return "", false
+}
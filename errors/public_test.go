@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPublicMessage(t *testing.T) {
+	RegisterMessages(DefaultLanguage, map[string]string{"test.order_locked": "Order {order_id} is locked"})
+	RegisterMessages("PT", map[string]string{"test.order_locked": "Pedido {order_id} bloqueado"})
+
+	err := fmt.Errorf("cancel: %w", New("test.order_locked", Warn, "Row lock held by tx 991 on db-7").With("order_id", "A-1"))
+	for want, languages := range map[string][]string{
+		"Pedido A-1 bloqueado": {"pt-BR"},
+		"Order A-1 is locked":  {"ja", "ko"},
+	} {
+		if got := PublicMessage(err, languages...); got != want {
+			t.Errorf("PublicMessage(%v) = %q, want %q", languages, got, want)
+		}
+	}
+
+	override := Wrap(err, "", Alert, "Cancel failed").WithPublic("Please retry later")
+	if got := PublicMessage(override, "pt"); got != "Please retry later" {
+		t.Errorf("PublicMessage() of an explicit message = %q", got)
+	}
+	if got := PublicMessage(New("test.unknown", Alert, "internal detail")); got != "" {
+		t.Errorf("PublicMessage() without template = %q, want empty", got)
+	}
+	if got := PublicMessage(fmt.Errorf("plain")); got != "" {
+		t.Errorf("PublicMessage() of a foreign error = %q, want empty", got)
+	}
+}
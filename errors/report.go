@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// UnregisteredCode is the code label of errors whose code is not in the catalog
+	UnregisteredCode = "unregistered"
+	// UnknownComponent is the component label of errors whose code is not in the catalog
+	UnknownComponent = "unknown"
+)
+
+// Report hands an error to the hooks registered for its severity with
+// ReportedEvent, importing apm/prometheus counts it in gokit_errors_total. It
+// does nothing for a nil error.
+func Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	fireHooks(ctx, ReportedEvent, err, GetSeverity(err), -1)
+}
+
+// Component returns the component of a code, the part before its first
+// dot, e.g. "cache" for "cache.key_not_exist"
+func Component(code string) string {
+	if i := strings.IndexByte(code, '.'); i > 0 {
+		return code[:i]
+	}
+	return UnknownComponent
+}
@@ -0,0 +1,11 @@
+package errors
+
+import "testing"
+
+func TestComponent(t *testing.T) {
+	for code, want := range map[string]string{"cache.key_not_exist": "cache", "plain": UnknownComponent, ".x": UnknownComponent} {
+		if got := Component(code); got != want {
+			t.Errorf("Component(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
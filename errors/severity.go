@@ -0,0 +1,45 @@
+package errors
+
+// severityRank orders the severities from the least to the most severe
+var severityRank = map[Severity]int{
+	NoneSeverity: 0,
+	Warn:         1,
+	Critical:     2,
+	Alert:        3,
+	Emergency:    4,
+	Fatal:        5,
+}
+
+// Rank returns the position of the severity in the order
+// NoneSeverity < Warn < Critical < Alert < Emergency < Fatal. Unknown
+// severities rank as NoneSeverity.
+func (s Severity) Rank() int {
+	return severityRank[s]
+}
+
+// AtLeast reports whether the severity is as severe as min or more
+func (s Severity) AtLeast(min Severity) bool {
+	return s.Rank() >= min.Rank()
+}
+
+// CompareSeverity returns -1, 0 or 1 when a is less, as or more severe than b
+func CompareSeverity(a, b Severity) int {
+	switch ra, rb := a.Rank(), b.Rank(); {
+	case ra < rb:
+		return -1
+	case ra > rb:
+		return 1
+	}
+	return 0
+}
+
+// MaxSeverity returns the highest of the severities, NoneSeverity when empty
+func MaxSeverity(severities ...Severity) Severity {
+	highest := NoneSeverity
+	for _, severity := range severities {
+		if severity.Rank() > highest.Rank() {
+			highest = severity
+		}
+	}
+	return highest
+}
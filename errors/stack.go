@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+const maxStackDepth = 32
+
+var stackCapture = struct {
+	sync.RWMutex
+	enabled    bool
+	severities map[Severity]bool
+}{severities: make(map[Severity]bool)}
+
+// SetStackCapture enables or disables the capture of the call stack by New
+// and Wrap for every severity without a specific setting. Capture is disabled
+// by default.
+func SetStackCapture(enabled bool) {
+	stackCapture.Lock()
+	defer stackCapture.Unlock()
+	stackCapture.enabled = enabled
+}
+
+// SetSeverityStackCapture enables or disables the capture of the call stack
+// for errors of the given severity, overriding SetStackCapture
+func SetSeverityStackCapture(severity Severity, enabled bool) {
+	stackCapture.Lock()
+	defer stackCapture.Unlock()
+	stackCapture.severities[severity] = enabled
+}
+
+func captureEnabled(severity Severity) bool {
+	stackCapture.RLock()
+	defer stackCapture.RUnlock()
+	if enabled, ok := stackCapture.severities[severity]; ok {
+		return enabled
+	}
+	return stackCapture.enabled
+}
+
+// callers returns the program counters of the stack starting at the caller
+// of the exported constructor
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(4, pcs)
+	return pcs[:n]
+}
+
+// StackTrace returns the stack captured when the error was created, or the
+// one of its cause when none was captured
+func (e *Error) StackTrace() []runtime.Frame {
+	if len(e.stack) == 0 {
+		var tracer interface{ StackTrace() []runtime.Frame }
+		if e.Cause != nil && As(e.Cause, &tracer) {
+			return tracer.StackTrace()
+		}
+		return nil
+	}
+
+	frames := runtime.CallersFrames(e.stack)
+	out := make([]runtime.Frame, 0, len(e.stack))
+	for {
+		frame, more := frames.Next()
+		out = append(out, frame)
+		if !more {
+			return out
+		}
+	}
+}
+
+// Format implements fmt.Formatter, %+v prints the message followed by the
+// metadata and the stack trace
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			metadata := GetMetadata(e)
+			for i, key := range metadataKeys(metadata) {
+				sep := " "
+				if i == 0 {
+					sep = "\n"
+				}
+				fmt.Fprintf(s, "%s%s=%v", sep, key, metadata[key])
+			}
+			for _, frame := range e.StackTrace() {
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+		}
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStackCapture(t *testing.T) {
+	defer func() {
+		SetStackCapture(false)
+		SetSeverityStackCapture(Warn, false)
+		SetSeverityStackCapture(Critical, false)
+	}()
+
+	if frames := New("", Critical, "no stack").StackTrace(); len(frames) != 0 {
+		t.Errorf("stack captured while disabled: %v", frames)
+	}
+
+	SetSeverityStackCapture(Critical, true)
+	err := New("", Critical, "boom")
+	frames := err.StackTrace()
+	if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, "TestStackCapture") {
+		t.Fatalf("StackTrace() should start at the caller, got %v", frames)
+	}
+	if len(New("", Warn, "cheap").StackTrace()) != 0 {
+		t.Error("stack captured for a severity without capture")
+	}
+
+	SetStackCapture(true)
+	SetSeverityStackCapture(Warn, false)
+	if len(New("", Alert, "global").StackTrace()) == 0 || len(New("", Warn, "override").StackTrace()) != 0 {
+		t.Error("per severity setting should override the global one")
+	}
+
+	wrapped := Wrap(err, "", Critical, "wrapped")
+	if wrapped.stack != nil || wrapped.StackTrace()[0] != frames[0] {
+		t.Error("Wrap should keep the stack of its cause")
+	}
+	if len(Wrap(io.EOF, "", Critical, "wrapped").StackTrace()) == 0 {
+		t.Error("Wrap should capture the stack of a foreign cause")
+	}
+
+	out := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(out, "boom\n") || !strings.Contains(out, "stack_test.go:") {
+		t.Errorf("%%+v = %q", out)
+	}
+	if got := fmt.Sprintf("%v %s %q", err, err, err); got != `boom boom "boom"` {
+		t.Errorf("plain verbs = %q", got)
+	}
+}
@@ -1,11 +1,25 @@
 package errors
 
+import "time"
+
 type (
 	// Error defines the attributes of an error
 	Error struct {
 		Code        string
 		Severity    Severity
 		Description []interface{}
+		// Cause is the underlying error, if any
+		Cause error
+		// Public is the user-facing message, Description is internal
+		Public string
+		// Metadata holds key/value context set with With
+		Metadata map[string]interface{}
+		// Class tells whether the failed operation can be retried
+		Class Class
+		// RetryAfter is the delay before retrying a throttled operation
+		RetryAfter time.Duration
+
+		stack []uintptr
 	}
 )
 
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/kumarabd/gokit/apm/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type asyncEntry struct {
+	level zerolog.Level
+	data  []byte
+}
+
+// asyncWriter buffers entries in a bounded ring and writes them to the next
+// writer from a single background goroutine
+type asyncWriter struct {
+	next   zerolog.LevelWriter
+	app    string
+	policy OverflowPolicy
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	ring    []asyncEntry
+	head    int
+	count   int
+	writing bool
+	closed  bool
+	done    chan struct{}
+}
+
+func newAsyncWriter(next zerolog.LevelWriter, app string, opts AsyncOptions) (*asyncWriter, error) {
+	policy := opts.Policy
+	switch policy {
+	case "":
+		policy = BlockPolicy
+	case BlockPolicy, DropOldestPolicy, DropNewestPolicy:
+	default:
+		return nil, ErrInvalidOverflowPolicy(policy)
+	}
+
+	w := &asyncWriter{
+		next:   next,
+		app:    app,
+		policy: policy,
+		ring:   make([]asyncEntry, opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w, nil
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *asyncWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return w.next.WriteLevel(level, p)
+	}
+
+	for w.count == len(w.ring) {
+		switch w.policy {
+		case DropNewestPolicy:
+			w.mu.Unlock()
+			w.dropped(level)
+			return len(p), nil
+		case DropOldestPolicy:
+			oldest := w.ring[w.head]
+			w.ring[w.head] = asyncEntry{}
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dropped(oldest.level)
+		default:
+			w.cond.Wait()
+			if w.closed {
+				w.mu.Unlock()
+				return w.next.WriteLevel(level, p)
+			}
+		}
+	}
+
+	// zerolog reuses its buffers once Write returns, keep a copy
+	data := make([]byte, len(p))
+	copy(data, p)
+	w.ring[(w.head+w.count)%len(w.ring)] = asyncEntry{level: level, data: data}
+	w.count++
+	prometheus.LogQueueDepth.WithLabelValues(w.app).Set(float64(w.count))
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+func (w *asyncWriter) dropped(level zerolog.Level) {
+	prometheus.LogsDropped.WithLabelValues(w.app, level.String(), "overflow").Inc()
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+
+	w.mu.Lock()
+	for {
+		for w.count == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.count == 0 && w.closed {
+			w.mu.Unlock()
+			return
+		}
+
+		entry := w.ring[w.head]
+		w.ring[w.head] = asyncEntry{}
+		w.head = (w.head + 1) % len(w.ring)
+		w.count--
+		w.writing = true
+		prometheus.LogQueueDepth.WithLabelValues(w.app).Set(float64(w.count))
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		_, _ = w.next.WriteLevel(entry.level, entry.data)
+
+		w.mu.Lock()
+		w.writing = false
+		w.cond.Broadcast()
+	}
+}
+
+// Sync blocks until every buffered entry has been handed to the next writer
+func (w *asyncWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for (w.count > 0 || w.writing) && !w.closed {
+		w.cond.Wait()
+	}
+	return nil
+}
+
+// Close drains the buffer and stops the background goroutine, later
+// writes go straight to the next writer
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
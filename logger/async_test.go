@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// gateWriter blocks every write until the gate is opened
+type gateWriter struct {
+	gate chan struct{}
+	out  *syncBuffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.out.Write(p)
+}
+
+func TestAsyncWriterPolicies(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   []string
+		drop   []string
+	}{
+		{DropNewestPolicy, []string{"e0", "e1", "e2"}, []string{"e3", "e4"}},
+		{DropOldestPolicy, []string{"e0", "e3", "e4"}, []string{"e1", "e2"}},
+	}
+
+	for _, tt := range tests {
+		next := &gateWriter{gate: make(chan struct{}), out: &syncBuffer{}}
+		w, err := newAsyncWriter(zerolog.LevelWriterAdapter{Writer: next}, "test", AsyncOptions{BufferSize: 2, Policy: tt.policy})
+		if err != nil {
+			t.Fatalf("newAsyncWriter() error = %v", err)
+		}
+
+		// e0 is picked up by the background goroutine and blocks on the gate
+		_, _ = w.WriteLevel(zerolog.InfoLevel, []byte("e0\n"))
+		for {
+			w.mu.Lock()
+			writing := w.writing
+			w.mu.Unlock()
+			if writing {
+				break
+			}
+			runtime.Gosched()
+		}
+		for _, entry := range []string{"e1", "e2", "e3", "e4"} {
+			_, _ = w.WriteLevel(zerolog.InfoLevel, []byte(entry+"\n"))
+		}
+		close(next.gate)
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		out := next.out.String()
+		for _, entry := range tt.want {
+			if !strings.Contains(out, entry) {
+				t.Errorf("%s: %s missing from %q", tt.policy, entry, out)
+			}
+		}
+		for _, entry := range tt.drop {
+			if strings.Contains(out, entry) {
+				t.Errorf("%s: %s should have been dropped from %q", tt.policy, entry, out)
+			}
+		}
+	}
+}
+
+func TestHandlerCloseFlushesAsync(t *testing.T) {
+	buf := &syncBuffer{}
+	log, err := New("test", Options{
+		Sinks: []SinkOptions{{Kind: WriterSink, Writer: buf}},
+		Async: AsyncOptions{BufferSize: 16},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		log.Info().Int("i", i).Msg("queued")
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := strings.Count(buf.String(), "queued"); got != 100 {
+		t.Errorf("entries after Close = %d, want 100", got)
+	}
+}
+
+func TestInvalidOverflowPolicy(t *testing.T) {
+	if _, err := New("test", Options{Async: AsyncOptions{BufferSize: 1, Policy: "panic"}}); err == nil {
+		t.Error("New() with unknown policy should fail")
+	}
+}
@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kumarabd/gokit/tracing/otel"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// SuccessOutcome is recorded when the action succeeded
+	SuccessOutcome AuditOutcome = "success"
+	// FailureOutcome is recorded when the action failed
+	FailureOutcome AuditOutcome = "failure"
+	// DeniedOutcome is recorded when the actor was not allowed to act
+	DeniedOutcome AuditOutcome = "denied"
+)
+
+// AuditOutcome describes how an audited action ended
+type AuditOutcome string
+
+// AuditOptions configures the audit stream
+type AuditOptions struct {
+	// Sink receives the audit entries, it must be distinct from the operational sinks
+	Sink SinkOptions `json:"sink,omitempty" yaml:"sink,omitempty"`
+}
+
+// AuditEntry is the fixed schema of the audit stream. Sequence, PrevHash and
+// Hash are set by Audit.Record and chain every entry to the previous one.
+type AuditEntry struct {
+	Time     time.Time         `json:"time"`
+	App      string            `json:"app"`
+	Actor    string            `json:"actor"`
+	Action   string            `json:"action"`
+	Resource string            `json:"resource"`
+	Outcome  AuditOutcome      `json:"outcome"`
+	Reason   string            `json:"reason,omitempty"`
+	TraceID  string            `json:"trace_id,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Sequence uint64            `json:"seq"`
+	PrevHash string            `json:"prev_hash"`
+	Hash     string            `json:"hash"`
+}
+
+// Audit writes tamper-evident entries to a dedicated sink. Each entry carries
+// the hash of the previous one so that any modification, insertion or
+// removal breaks the chain and is detected by VerifyAudit.
+type Audit struct {
+	app      string
+	out      zerolog.LevelWriter
+	resource io.Writer
+
+	mu       sync.Mutex
+	sequence uint64
+	prevHash string
+}
+
+// NewAudit instantiates the audit stream of an application. File sinks resume
+// the chain from the last entry already present in the file.
+func NewAudit(appname string, opts AuditOptions) (*Audit, error) {
+	if opts.Sink.Kind == "" {
+		return nil, ErrInvalidSink(opts.Sink.Kind, "audit requires an explicit sink")
+	}
+	if opts.Sink.Format == ConsoleLogFormat {
+		return nil, ErrInvalidSink(opts.Sink.Kind, "audit entries must be written as JSON")
+	}
+
+	a := &Audit{app: appname}
+	if opts.Sink.Kind == FileSink && opts.Sink.File.Path != "" {
+		last, err := lastAuditEntry(opts.Sink.File.Path)
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			a.sequence, a.prevHash = last.Sequence, last.Hash
+		}
+	}
+
+	out, resource, err := newSink(appname, opts.Sink)
+	if err != nil {
+		return nil, err
+	}
+	a.out, a.resource = out, resource
+	return a, nil
+}
+
+// Record appends an entry to the audit stream. Time and App are filled in
+// when empty, and the trace id is taken from the span active in ctx.
+func (a *Audit) Record(ctx context.Context, entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	if entry.App == "" {
+		entry.App = a.app
+	}
+	if entry.TraceID == "" {
+		entry.TraceID = otel.TraceID(ctx)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry.Sequence = a.sequence + 1
+	entry.PrevHash = a.prevHash
+	hash, err := auditHash(entry)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := a.out.WriteLevel(zerolog.NoLevel, append(line, '\n')); err != nil {
+		return err
+	}
+	a.sequence, a.prevHash = entry.Sequence, entry.Hash
+	return nil
+}
+
+// Close flushes and releases the audit sink
+func (a *Audit) Close() error {
+	writers := []io.Writer{a.resource}
+	err := syncAll(writers)
+	if cerr := closeAll(writers); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// AuditAnchor is a position in an audit stream, the sequence and hash of an
+// entry. The zero anchor precedes the first entry of a stream.
+type AuditAnchor struct {
+	Sequence uint64
+	Hash     string
+}
+
+// VerifyAudit checks the hash chain of an audit stream whose first entry
+// follows after, the zero anchor for a complete stream, so removing the head
+// of the stream is detected. A rotated file is verified after the last entry
+// of the previous file. It returns the anchor of the last entry, which the
+// caller compares with an external record to detect a truncated tail, and
+// the number of verified entries.
+func VerifyAudit(r io.Reader, after AuditAnchor) (AuditAnchor, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	last := after
+	count := 0
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		entry := &AuditEntry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			return last, count, ErrAuditTampered(line, "entry is not valid JSON")
+		}
+		hash, err := auditHash(*entry)
+		if err != nil {
+			return last, count, err
+		}
+		if hash != entry.Hash {
+			return last, count, ErrAuditTampered(line, "hash does not match the entry")
+		}
+		if entry.PrevHash != last.Hash {
+			return last, count, ErrAuditTampered(line, "previous hash does not match the previous entry")
+		}
+		if entry.Sequence != last.Sequence+1 {
+			return last, count, ErrAuditTampered(line, "sequence is not contiguous")
+		}
+		last = AuditAnchor{Sequence: entry.Sequence, Hash: entry.Hash}
+		count++
+	}
+	return last, count, scanner.Err()
+}
+
+// auditHash is the hex sha256 of the entry encoded without its own hash
+func auditHash(entry AuditEntry) (string, error) {
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lastAuditEntry reads the last entry of an audit file, nil when there is none
+func lastAuditEntry(path string) (*AuditEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read backwards in chunks until the last complete line is found
+	const chunk = 64 * 1024
+	var tail []byte
+	for offset := info.Size(); offset > 0; {
+		size := int64(chunk)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+
+		buf := make([]byte, size)
+		if _, err := file.ReadAt(buf, offset); err != nil {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+
+		trimmed := bytes.TrimRight(tail, "\r\n ")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 || offset == 0 {
+			last := trimmed[i+1:]
+			if len(last) == 0 {
+				return nil, nil
+			}
+			entry := &AuditEntry{}
+			if err := json.Unmarshal(last, entry); err != nil {
+				return nil, ErrAuditTampered(0, "last entry is not valid JSON")
+			}
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
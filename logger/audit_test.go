@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditChainResumesAndVerifies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	opts := AuditOptions{Sink: SinkOptions{Kind: FileSink, File: FileOptions{Path: path}}}
+
+	for _, actor := range []string{"alice", "bob"} {
+		audit, err := NewAudit("test", opts)
+		if err != nil {
+			t.Fatalf("NewAudit() error = %v", err)
+		}
+		for _, action := range []string{"login", "delete"} {
+			entry := AuditEntry{Actor: actor, Action: action, Resource: "user/42", Outcome: SuccessOutcome}
+			if err := audit.Record(context.Background(), entry); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+		if err := audit.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	last, n, err := VerifyAudit(bytes.NewReader(data), AuditAnchor{})
+	if err != nil || n != 4 || last.Sequence != 4 || last.Hash == "" {
+		t.Fatalf("VerifyAudit() = %+v, %d, %v, want 4 entries", last, n, err)
+	}
+	if !strings.Contains(string(data), `"seq":4`) {
+		t.Errorf("sequence did not resume across instances: %s", data)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for name, tampered := range map[string][]string{
+		"modified":  {lines[0], strings.Replace(lines[1], "delete", "read", 1), lines[2], lines[3]},
+		"removed":   {lines[0], lines[2], lines[3]},
+		"reordered": {lines[0], lines[2], lines[1], lines[3]},
+		"headless":  {lines[1], lines[2], lines[3]},
+	} {
+		if _, _, err := VerifyAudit(strings.NewReader(strings.Join(tampered, "\n")), AuditAnchor{}); err == nil {
+			t.Errorf("VerifyAudit() of %s stream should fail", name)
+		}
+	}
+
+	// A rotated file verifies after the last entry of the previous one
+	head, _, err := VerifyAudit(strings.NewReader(strings.Join(lines[:2], "\n")), AuditAnchor{})
+	if err != nil {
+		t.Fatalf("VerifyAudit() of the head error = %v", err)
+	}
+	if _, n, err := VerifyAudit(strings.NewReader(strings.Join(lines[2:], "\n")), head); err != nil || n != 2 {
+		t.Errorf("VerifyAudit() of the rotated tail = %d, %v, want 2 entries", n, err)
+	}
+	// A truncated tail only shows in the last anchor
+	if tail, _, err := VerifyAudit(strings.NewReader(strings.Join(lines[:3], "\n")), AuditAnchor{}); err != nil || tail == last {
+		t.Errorf("VerifyAudit() of a truncated stream = %+v, %v, want an anchor before %+v", tail, err, last)
+	}
+}
+
+func TestAuditRequiresJSONSink(t *testing.T) {
+	for _, sink := range []SinkOptions{
+		{},
+		{Kind: WriterSink, Writer: &bytes.Buffer{}, Format: ConsoleLogFormat},
+	} {
+		if _, err := NewAudit("test", AuditOptions{Sink: sink}); err == nil {
+			t.Errorf("NewAudit() with sink %+v should fail", sink)
+		}
+	}
+}
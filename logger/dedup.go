@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kumarabd/gokit/apm/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// RepeatedFieldName is the field carrying the number of collapsed duplicates
+const RepeatedFieldName = "repeated"
+
+type dedupEntry struct {
+	level zerolog.Level
+	line  []byte
+	count int
+}
+
+// dedupWriter writes the first occurrence of an entry straight away and
+// suppresses identical entries for the rest of the window. When the window
+// closes a single line carrying the repeat count is written for them.
+type dedupWriter struct {
+	next   zerolog.LevelWriter
+	app    string
+	window time.Duration
+
+	mu     sync.Mutex
+	seen   map[uint64]*dedupEntry
+	closed bool
+}
+
+func newDedupWriter(next zerolog.LevelWriter, app string, window time.Duration) *dedupWriter {
+	return &dedupWriter{
+		next:   next,
+		app:    app,
+		window: window,
+		seen:   make(map[uint64]*dedupEntry),
+	}
+}
+
+func (w *dedupWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *dedupWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	key := fingerprint(level, p)
+
+	w.mu.Lock()
+	if w.closed {
+		// No timer may write to the sinks once they are closed
+		w.mu.Unlock()
+		return w.next.WriteLevel(level, p)
+	}
+	if entry, ok := w.seen[key]; ok {
+		entry.count++
+		entry.line = append(entry.line[:0], p...)
+		w.mu.Unlock()
+		prometheus.LogsDropped.WithLabelValues(w.app, level.String(), "deduplicated").Inc()
+		return len(p), nil
+	}
+	w.seen[key] = &dedupEntry{level: level}
+	w.mu.Unlock()
+
+	time.AfterFunc(w.window, func() { w.flush(key) })
+	return w.next.WriteLevel(level, p)
+}
+
+// flush ends the window of an entry and writes the repeat summary if needed
+func (w *dedupWriter) flush(key uint64) {
+	w.mu.Lock()
+	entry, ok := w.seen[key]
+	delete(w.seen, key)
+	w.mu.Unlock()
+
+	if !ok || entry.count == 0 {
+		return
+	}
+	_, _ = w.next.WriteLevel(entry.level, withRepeated(entry.line, entry.count))
+}
+
+// fingerprint identifies an entry regardless of its timestamp
+func fingerprint(level zerolog.Level, p []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(level.String()))
+
+	entry := make(map[string]interface{})
+	if err := json.Unmarshal(p, &entry); err != nil {
+		_, _ = h.Write(p)
+		return h.Sum64()
+	}
+	delete(entry, zerolog.TimestampFieldName)
+	// Map keys are marshalled in sorted order which keeps the hash stable
+	data, _ := json.Marshal(entry)
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+// withRepeated appends the repeat count to a JSON entry
+func withRepeated(line []byte, count int) []byte {
+	return appendField(line, RepeatedFieldName, strconv.AppendInt(nil, int64(count), 10))
+}
+
+// Sync ends every open window, writing the pending repeat summaries
+func (w *dedupWriter) Sync() error {
+	w.mu.Lock()
+	keys := make([]uint64, 0, len(w.seen))
+	for key := range w.seen {
+		keys = append(keys, key)
+	}
+	w.mu.Unlock()
+
+	for _, key := range keys {
+		w.flush(key)
+	}
+	return nil
+}
+
+// Close writes the pending repeat summaries and stops deduplicating, so the
+// timers of the windows do not write to closed sinks
+func (w *dedupWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	return w.Sync()
+}
@@ -0,0 +1,54 @@
+package logger
+
+import "github.com/kumarabd/gokit/errors"
+
+var (
+	errInvalidRedactPattern = errors.Register("logger.invalid_redact_pattern", errors.Alert, "Invalid redaction pattern %s",
+		"A pattern of Redact.Patterns is not a valid regular expression.")
+	errInvalidLevel = errors.Register("logger.invalid_level", errors.Alert, "Invalid log level %s",
+		"A sink level is not one of debug, info, warn or error.")
+	errInvalidSink = errors.Register("logger.invalid_sink", errors.Alert, "Invalid %s sink: %s",
+		"A sink is of an unknown kind or misses a required setting such as the file path or the push URL.")
+	errInvalidOverflowPolicy = errors.Register("logger.invalid_overflow_policy", errors.Alert, "Invalid overflow policy %s",
+		"Async.Policy is not one of block, drop_oldest or drop_newest.")
+	errPushFailed = errors.Register("logger.push_failed", errors.Warn, "Push to %s failed: %s",
+		"An HTTP push sink endpoint rejected a batch. The batch is retried, then spooled to disk when a spool directory is configured. Batches refused with a 4xx status other than 408 and 429 are dropped.")
+	errAuditTampered = errors.Register("logger.audit_tampered", errors.Critical, "Audit entry at line %d failed verification: %s",
+		"An audit log entry was modified, removed or reordered after it was written. Investigate the audit file from the reported line on.")
+)
+
+// ErrInvalidRedactPattern is returned when a redaction pattern does not compile
+func ErrInvalidRedactPattern(pattern string, err error) error {
+	return errInvalidRedactPattern.Wrap(err, pattern)
+}
+
+// ErrInvalidLevel is returned when a configured log level is unknown
+func ErrInvalidLevel(level Level) error {
+	return errInvalidLevel.New(level)
+}
+
+// ErrInvalidSink is returned when a sink is misconfigured
+func ErrInvalidSink(kind SinkKind, reason string) error {
+	return errInvalidSink.New(kind, reason)
+}
+
+// ErrInvalidOverflowPolicy is returned when an asynchronous writer policy is unknown
+func ErrInvalidOverflowPolicy(policy OverflowPolicy) error {
+	return errInvalidOverflowPolicy.New(policy)
+}
+
+// ErrPushFailed is returned when a push endpoint rejects a batch
+func ErrPushFailed(url, status string) error {
+	return errPushFailed.New(url, status)
+}
+
+// ErrPushRejected is returned when a push endpoint refuses a batch for good,
+// such as Loki refusing entries out of order, retrying it would fail again
+func ErrPushRejected(url, status string) error {
+	return errPushFailed.New(url, status).WithClass(errors.PermanentClass)
+}
+
+// ErrAuditTampered is returned when an audit stream fails verification
+func ErrAuditTampered(line int, reason string) error {
+	return errAuditTampered.New(line, reason)
+}
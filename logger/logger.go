@@ -1,7 +1,7 @@
 package logger
 
 import (
-	"os"
+	"io"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zerologr"
@@ -11,18 +11,100 @@ import (
 // Handler wraps zerolog.Logger to allow method definitions
 type Handler struct {
 	zerolog.Logger
+
+	// writers owned by the handler, ordered from the logger to the sinks
+	writers []io.Writer
 }
 
 // New instantiates bucky logger instance
 func New(appname string, opts Options) (*Handler, error) {
-	// Use built-in console format (human-readable)
-	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).With().Timestamp().Logger()
+	installMarshalers()
+
+	// Without sinks, use built-in console format (human-readable) on stdout
+	out, owned, err := newSinks(appname, opts.Sinks)
+	if err != nil {
+		return nil, err
+	}
+	writers := owned
+
+	if opts.Dedup.Window > 0 {
+		dedup := newDedupWriter(out, appname, opts.Dedup.Window)
+		out, writers = dedup, append([]io.Writer{dedup}, writers...)
+	}
+
+	// Redaction runs on the encoded entry so it applies to both the
+	// zerolog Handler and the logr adapter sharing the same writer
+	redactor, err := newRedactor(opts.Redact)
+	if err != nil {
+		closeAll(writers)
+		return nil, err
+	}
+	out = &processWriter{next: out, processors: []processor{redactor.process}, accepts: redactor.accepts}
+
+	if opts.Async.BufferSize > 0 {
+		async, err := newAsyncWriter(out, appname, opts.Async)
+		if err != nil {
+			closeAll(writers)
+			return nil, err
+		}
+		out, writers = async, append([]io.Writer{async}, writers...)
+	}
+
+	// The stack of the logging call is captured before any asynchronous hop
+	if opts.StackTrace {
+		out = &stackWriter{next: out}
+	}
+
+	ctx := zerolog.New(out).With().Timestamp()
+	if opts.Caller {
+		ctx = ctx.Caller()
+	}
+	if opts.StackTrace {
+		ctx = ctx.Stack()
+	}
+	logger := ctx.Logger().Hook(traceHook{spanEvents: opts.SpanEvents})
+	if opts.GoroutineID {
+		logger = logger.Hook(goroutineHook{})
+	}
 	logger = logger.With().Str("app", appname).Logger()
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
 
-	return &Handler{logger}, nil
+	sampler, err := newSampler(appname, opts.Sampling)
+	if err != nil {
+		closeAll(writers)
+		return nil, err
+	}
+	if sampler != nil {
+		logger = logger.Sample(sampler)
+	}
+
+	return &Handler{Logger: logger, writers: writers}, nil
 }
 
 func (l *Handler) AsLogrLogger() logr.Logger {
 	return zerologr.New(&l.Logger)
 }
+
+// Sync flushes buffered entries down to the sinks
+func (l *Handler) Sync() error {
+	return syncAll(l.writers)
+}
+
+// Close flushes buffered entries and releases the sinks owned by the handler.
+// Entries logged after Close are not guaranteed to reach the sinks.
+func (l *Handler) Close() error {
+	err := syncAll(l.writers)
+	if cerr := closeAll(l.writers); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// parseLevel converts a configured level into its zerolog counterpart
+func parseLevel(level Level) (zerolog.Level, error) {
+	lvl, err := zerolog.ParseLevel(string(level))
+	if err != nil || level == "" {
+		return zerolog.NoLevel, ErrInvalidLevel(level)
+	}
+	return lvl, nil
+}
@@ -0,0 +1,192 @@
+// Package loggertest captures the output of a logger.Handler so tests can
+// assert on structured entries instead of writing to stdout.
+package loggertest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/kumarabd/gokit/logger"
+	"github.com/rs/zerolog"
+)
+
+// Entry is a decoded log entry
+type Entry struct {
+	Level   logger.Level
+	Message string
+	// Fields holds every other field of the entry, numbers are decoded as float64
+	Fields map[string]interface{}
+}
+
+// Recorder holds a logger.Handler writing to an in-memory buffer
+type Recorder struct {
+	handler *logger.Handler
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// New returns a Recorder with default logger options
+func New(t testing.TB) *Recorder {
+	return NewWithOptions(t, logger.Options{})
+}
+
+// NewWithOptions returns a Recorder built with opts, the configured sinks are
+// replaced by the in-memory capture. The handler is closed when the test ends.
+func NewWithOptions(t testing.TB, opts logger.Options) *Recorder {
+	t.Helper()
+
+	r := &Recorder{}
+	opts.Sinks = []logger.SinkOptions{{Kind: logger.WriterSink, Writer: r, Format: logger.JSONLogFormat}}
+	handler, err := logger.New(t.Name(), opts)
+	if err != nil {
+		t.Fatalf("loggertest: %v", err)
+	}
+	t.Cleanup(func() { _ = handler.Close() })
+
+	r.handler = handler
+	return r
+}
+
+// Write implements io.Writer for the capture sink
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+// Handler returns the logger writing to the recorder
+func (r *Recorder) Handler() *logger.Handler {
+	return r.handler
+}
+
+// Logr returns a logr.Logger writing to the recorder
+func (r *Recorder) Logr() logr.Logger {
+	return r.handler.AsLogrLogger()
+}
+
+// Reset discards every captured entry
+func (r *Recorder) Reset() {
+	_ = r.handler.Sync()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf.Reset()
+}
+
+// Entries returns the captured entries in the order they were written
+func (r *Recorder) Entries() []Entry {
+	_ = r.handler.Sync()
+	r.mu.Lock()
+	data := append([]byte(nil), r.buf.Bytes()...)
+	r.mu.Unlock()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			continue
+		}
+
+		entry := Entry{Fields: fields}
+		if level, ok := fields[zerolog.LevelFieldName].(string); ok {
+			entry.Level = logger.Level(level)
+		}
+		if msg, ok := fields[zerolog.MessageFieldName].(string); ok {
+			entry.Message = msg
+		}
+		delete(fields, zerolog.LevelFieldName)
+		delete(fields, zerolog.MessageFieldName)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Filter returns the captured entries of the given level
+func (r *Recorder) Filter(level logger.Level) []Entry {
+	var entries []Entry
+	for _, entry := range r.Entries() {
+		if entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Find returns the first entry with the given level and message whose fields
+// match keysAndValues. An empty level or message matches any entry.
+func (r *Recorder) Find(level logger.Level, msg string, keysAndValues ...interface{}) (Entry, bool) {
+	for _, entry := range r.Entries() {
+		if entry.Matches(level, msg, keysAndValues...) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// AssertLogged fails the test unless a matching entry was captured
+func (r *Recorder) AssertLogged(t testing.TB, level logger.Level, msg string, keysAndValues ...interface{}) {
+	t.Helper()
+	if _, ok := r.Find(level, msg, keysAndValues...); !ok {
+		t.Errorf("loggertest: no %s entry %q with %v in:\n%s", level, msg, keysAndValues, r.dump())
+	}
+}
+
+// AssertNotLogged fails the test if a matching entry was captured
+func (r *Recorder) AssertNotLogged(t testing.TB, level logger.Level, msg string, keysAndValues ...interface{}) {
+	t.Helper()
+	if entry, ok := r.Find(level, msg, keysAndValues...); ok {
+		t.Errorf("loggertest: unexpected %s entry %q with %v", entry.Level, entry.Message, entry.Fields)
+	}
+}
+
+// Matches reports whether the entry has the given level, message and fields.
+// Field values are compared through their fmt.Sprint representation, and
+// keys are resolved with Field.
+func (e Entry) Matches(level logger.Level, msg string, keysAndValues ...interface{}) bool {
+	if level != "" && e.Level != level {
+		return false
+	}
+	if msg != "" && e.Message != msg {
+		return false
+	}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		value, ok := e.Field(fmt.Sprint(keysAndValues[i]))
+		if !ok || fmt.Sprint(value) != fmt.Sprint(keysAndValues[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Field returns the value of a field, nested fields such as the code of an
+// error object are addressed with dotted keys, e.g. "error.code"
+func (e Entry) Field(key string) (interface{}, bool) {
+	if value, ok := e.Fields[key]; ok {
+		return value, true
+	}
+	var current interface{} = e.Fields
+	for _, part := range strings.Split(key, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = fields[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func (r *Recorder) dump() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.String()
+}
@@ -0,0 +1,44 @@
+package loggertest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kumarabd/gokit/logger"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := New(t)
+
+	rec.Handler().Info().Str("user_id", "42").Int("attempt", 3).Msg("login")
+	rec.Logr().Error(errors.New("boom"), "payment failed", "order_id", "A-1")
+
+	rec.AssertLogged(t, logger.InfoLogLevel, "login", "user_id", "42", "attempt", 3, "app", t.Name())
+	rec.AssertLogged(t, logger.ErrorLogLevel, "payment failed", "order_id", "A-1", "error", "boom")
+	rec.AssertNotLogged(t, logger.DebugLogLevel, "")
+
+	if got := len(rec.Filter(logger.ErrorLogLevel)); got != 1 {
+		t.Errorf("error entries = %d, want 1", got)
+	}
+
+	rec.Reset()
+	if got := len(rec.Entries()); got != 0 {
+		t.Errorf("entries after Reset = %d, want 0", got)
+	}
+}
+
+func TestRecorderWithOptions(t *testing.T) {
+	rec := NewWithOptions(t, logger.Options{
+		Redact: logger.RedactOptions{Fields: []string{"password"}},
+		Async:  logger.AsyncOptions{BufferSize: 4},
+	})
+
+	for i := 0; i < 10; i++ {
+		rec.Handler().Info().Str("password", "hunter2").Msg("signup")
+	}
+
+	if got := len(rec.Entries()); got != 10 {
+		t.Errorf("entries = %d, want 10", got)
+	}
+	rec.AssertNotLogged(t, "", "", "password", "hunter2")
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/kumarabd/gokit/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// ErrorMessageFieldName is the field of error objects carrying the message
+	ErrorMessageFieldName = "message"
+	// ErrorCodeFieldName is the field of error objects carrying the code
+	ErrorCodeFieldName = "code"
+	// ErrorSeverityFieldName is the field of error objects carrying the severity
+	ErrorSeverityFieldName = "severity"
+	// ErrorMetadataFieldName is the field of error objects carrying the metadata
+	ErrorMetadataFieldName = "metadata"
+	// ErrorPublicFieldName is the field of error objects carrying the
+	// user-facing message in the default language
+	ErrorPublicFieldName = "public"
+)
+
+var marshalersOnce sync.Once
+
+// installMarshalers sets the zerolog marshalers of the kit when the first
+// handler is created. zerolog only offers global marshalers, so they take over
+// the values specific to the kit and hand every other value to the marshalers
+// in place before: errors of the kit are written as objects, loggers with
+// stacks enabled write the stack of errors carrying one, and the struct fields
+// tagged with RedactTag are sealed, so that handlers mask them in their own
+// mode and other loggers write a [REDACTED] marker.
+func installMarshalers() {
+	marshalersOnce.Do(func() {
+		errorMarshaler, stackMarshaler, interfaceMarshaler := zerolog.ErrorMarshalFunc, zerolog.ErrorStackMarshaler, zerolog.InterfaceMarshalFunc
+		zerolog.ErrorMarshalFunc = func(err error) interface{} {
+			if err != nil && errors.Is(err) {
+				return errorObject{err: err}
+			}
+			return errorMarshaler(err)
+		}
+		zerolog.ErrorStackMarshaler = func(err error) interface{} {
+			if stack := marshalErrorStack(err); stack != nil || stackMarshaler == nil {
+				return stack
+			}
+			return stackMarshaler(err)
+		}
+		zerolog.InterfaceMarshalFunc = func(v interface{}) ([]byte, error) {
+			if v == nil || !tagSealer.hasTags(reflect.TypeOf(v)) {
+				return interfaceMarshaler(v)
+			}
+			return tagSealer.marshal(v)
+		}
+	})
+}
+
+// errorObject renders an error of the kit with its code, severity, metadata
+// and public message next to the internal message
+type errorObject struct {
+	err error
+}
+
+func (o errorObject) MarshalZerologObject(e *zerolog.Event) {
+	e.Str(ErrorMessageFieldName, o.err.Error())
+	if public := errors.PublicMessage(o.err); public != "" {
+		e.Str(ErrorPublicFieldName, public)
+	}
+	if code := errors.GetCode(o.err); code != "" {
+		e.Str(ErrorCodeFieldName, code)
+	}
+	e.Str(ErrorSeverityFieldName, string(errors.GetSeverity(o.err)))
+	if metadata := errors.GetMetadata(o.err); len(metadata) > 0 {
+		e.Fields(map[string]interface{}{ErrorMetadataFieldName: metadata})
+	}
+}
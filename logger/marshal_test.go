@@ -0,0 +1,84 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kumarabd/gokit/errors"
+	"github.com/kumarabd/gokit/logger"
+	"github.com/kumarabd/gokit/logger/loggertest"
+	"github.com/rs/zerolog"
+)
+
+func TestMarshalersKeepForeignValues(t *testing.T) {
+	log, err := logger.New("test", logger.Options{Sinks: []logger.SinkOptions{{Kind: logger.WriterSink, Writer: io.Discard}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer log.Close()
+
+	// Loggers outside the kit keep the encoding of values foreign to the kit
+	// and never write tagged fields in clear
+	var buf bytes.Buffer
+	foreign := zerolog.New(&buf)
+	foreign.Info().Err(fmt.Errorf("plain")).
+		Interface("point", struct{ X int }{1}).
+		Interface("credentials", credentials{User: "bob", Password: "hunter2"}).Msg("foreign")
+	entry := make(map[string]interface{})
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid entry %q: %v", buf.String(), err)
+	}
+	if entry["error"] != "plain" || !reflect.DeepEqual(entry["point"], map[string]interface{}{"X": float64(1)}) {
+		t.Errorf("entry = %v, want the plain error and point", entry)
+	}
+	if password, _ := entry["credentials"].(map[string]interface{})["password"].(string); !strings.HasPrefix(password, "[REDACTED]") || strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("entry = %s, want the tagged password masked", buf.String())
+	}
+}
+
+func TestErrorObjects(t *testing.T) {
+	rec := loggertest.New(t)
+	log := rec.Handler()
+
+	err := errors.New("order.rejected", errors.Warn, "Order rejected").With("order_id", "A-1").WithPublic("Your order was rejected")
+	log.Error().Err(fmt.Errorf("checkout: %w", err)).Msg("kit error")
+	log.Error().Err(fmt.Errorf("plain")).Msg("plain error")
+
+	rec.AssertLogged(t, logger.ErrorLogLevel, "kit error",
+		"error.message", "checkout: Order rejected",
+		"error.code", "order.rejected",
+		"error.severity", "warn",
+		"error.public", "Your order was rejected",
+		"error.metadata.order_id", "A-1")
+	rec.AssertLogged(t, logger.ErrorLogLevel, "plain error", "error", "plain")
+}
+
+type credentials struct {
+	User     string `json:"user"`
+	Password string `json:"password" redact:"true"`
+}
+
+func TestRedactTags(t *testing.T) {
+	rec := loggertest.NewWithOptions(t, logger.Options{Redact: logger.RedactOptions{Mode: logger.HashRedactMode}})
+	rec.Handler().Info().Interface("credentials", credentials{User: "bob", Password: "hunter2"}).Msg("login")
+	rec.Logr().Info("logr login", "credentials", credentials{User: "bob", Password: "hunter2"})
+
+	for _, msg := range []string{"login", "logr login"} {
+		entry, ok := rec.Find(logger.InfoLogLevel, msg)
+		if !ok {
+			t.Fatalf("entry %q not logged", msg)
+		}
+		password, _ := entry.Field("credentials.password")
+		if s, _ := password.(string); !strings.HasPrefix(s, "sha256:") {
+			t.Errorf("%s: password = %v, want the hash of the value", msg, password)
+		}
+		if user, _ := entry.Field("credentials.user"); user != "bob" {
+			t.Errorf("%s: user = %v, want bob", msg, user)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kumarabd/gokit/apm/prometheus"
+	"github.com/kumarabd/gokit/errors"
+	"github.com/rs/zerolog"
+)
+
+const spoolExt = ".batch"
+
+// pushEncoder turns a batch of JSON entries into a request body
+type pushEncoder func(batch [][]byte) ([]byte, error)
+
+// pushWriter batches entries in memory and pushes them to an HTTP endpoint.
+// Batches that cannot be delivered after the retries are spooled to disk and
+// replayed once the endpoint accepts requests again. Batches the endpoint
+// refuses for good are dropped.
+type pushWriter struct {
+	app         string
+	opts        HTTPOptions
+	contentType string
+	encode      pushEncoder
+	// rejected counts the entries refused in a successful reply
+	rejected func(reply []byte) int
+	client   *http.Client
+
+	mu           sync.Mutex
+	pending      [][]byte
+	pendingBytes int
+	closed       bool
+
+	sendMu  sync.Mutex
+	flush   chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+}
+
+func newPushWriter(app string, kind SinkKind, opts HTTPOptions) (*pushWriter, error) {
+	if opts.URL == "" {
+		return nil, ErrInvalidSink(kind, "url is required")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	switch {
+	case opts.MaxRetries == 0:
+		opts.MaxRetries = 3
+	case opts.MaxRetries < 0:
+		opts.MaxRetries = 0
+	}
+	if opts.MaxBufferBytes <= 0 {
+		opts.MaxBufferBytes = 4 << 20
+	}
+	if opts.MaxSpoolBytes <= 0 {
+		opts.MaxSpoolBytes = 64 << 20
+	}
+	if opts.SpoolDir != "" {
+		if err := os.MkdirAll(opts.SpoolDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	w := &pushWriter{
+		app:     app,
+		opts:    opts,
+		client:  &http.Client{Timeout: opts.Timeout},
+		flush:   make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	switch kind {
+	case LokiSink:
+		if len(w.opts.Labels) == 0 {
+			w.opts.Labels = []string{"app", zerolog.LevelFieldName}
+		}
+		w.contentType, w.encode = "application/json", w.encodeLoki
+	case ElasticsearchSink:
+		if w.opts.Index == "" {
+			w.opts.Index = "logs"
+		}
+		w.contentType, w.encode, w.rejected = "application/x-ndjson", w.encodeBulk, bulkRejected
+	default:
+		return nil, ErrInvalidSink(kind, "unknown kind")
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *pushWriter) Write(p []byte) (int, error) {
+	entry := bytes.TrimSpace(p)
+	if len(entry) == 0 {
+		return len(p), nil
+	}
+	data := make([]byte, len(entry))
+	copy(data, entry)
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	// Keep the memory footprint bounded by discarding the oldest entries
+	for len(w.pending) > 0 && w.pendingBytes+len(data) > w.opts.MaxBufferBytes {
+		w.pendingBytes -= len(w.pending[0])
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.dropped(1, "overflow")
+	}
+	w.pending = append(w.pending, data)
+	w.pendingBytes += len(data)
+	full := len(w.pending) >= w.opts.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func (w *pushWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.flush:
+		case <-w.closing:
+			w.drain()
+			return
+		}
+		w.drain()
+	}
+}
+
+// drain sends every pending entry in batches of BatchSize
+func (w *pushWriter) drain() {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+
+	for {
+		w.mu.Lock()
+		n := len(w.pending)
+		if n > w.opts.BatchSize {
+			n = w.opts.BatchSize
+		}
+		batch := w.pending[:n:n]
+		w.pending = w.pending[n:]
+		for _, entry := range batch {
+			w.pendingBytes -= len(entry)
+		}
+		w.mu.Unlock()
+
+		if len(batch) == 0 {
+			return
+		}
+		w.deliver(batch)
+	}
+}
+
+func (w *pushWriter) deliver(batch [][]byte) {
+	body, err := w.encode(batch)
+	if err != nil {
+		w.dropped(len(batch), "encode")
+		return
+	}
+	rejected, err := w.send(body)
+	switch {
+	case errors.IsPermanent(err):
+		w.dropped(len(batch), "rejected")
+	case err != nil:
+		w.spool(body, len(batch))
+		return
+	case rejected > 0:
+		w.dropped(rejected, "rejected")
+	}
+	w.replay()
+}
+
+// send posts a body, retrying with exponential backoff unless the endpoint
+// refuses it for good
+func (w *pushWriter) send(body []byte) (int, error) {
+	backoff := 200 * time.Millisecond
+	var err error
+	for attempt := 0; attempt <= w.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+			case <-w.closing:
+				// Do not hold shutdown for the retries, the batch gets spooled
+				return 0, err
+			}
+		}
+		var rejected int
+		if rejected, err = w.post(body); err == nil || errors.IsPermanent(err) {
+			return rejected, err
+		}
+	}
+	return 0, err
+}
+
+// post sends a body and returns the number of entries refused by an
+// endpoint accepting the rest. Refusals with a 4xx status other than 408 and
+// 429 are permanent errors, other failures may succeed when retried.
+func (w *pushWriter) post(body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", w.contentType)
+	for key, value := range w.opts.Headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	reply, _ := io.ReadAll(res.Body)
+	switch {
+	case res.StatusCode == http.StatusRequestTimeout || res.StatusCode == http.StatusTooManyRequests:
+		return 0, ErrPushFailed(w.opts.URL, res.Status)
+	case res.StatusCode >= 400 && res.StatusCode <= 499:
+		return 0, ErrPushRejected(w.opts.URL, res.Status)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return 0, ErrPushFailed(w.opts.URL, res.Status)
+	}
+	if w.rejected == nil {
+		return 0, nil
+	}
+	return w.rejected(reply), nil
+}
+
+// spool keeps an undelivered body on disk, dropping the oldest spooled
+// batches when the spool grows past MaxSpoolBytes
+func (w *pushWriter) spool(body []byte, entries int) {
+	if w.opts.SpoolDir == "" {
+		w.dropped(entries, "push_failed")
+		return
+	}
+
+	// The name orders the batches and records their number of entries
+	name := filepath.Join(w.opts.SpoolDir, strconv.FormatInt(time.Now().UnixNano(), 10)+"-"+strconv.Itoa(entries)+spoolExt)
+	if err := os.WriteFile(name, body, 0644); err != nil {
+		w.dropped(entries, "push_failed")
+		return
+	}
+
+	files := w.spooled()
+	var size int64
+	for i := len(files) - 1; i >= 0; i-- {
+		size += files[i].size
+		if size > w.opts.MaxSpoolBytes {
+			_ = os.Remove(files[i].path)
+		}
+	}
+}
+
+// replay resends spooled batches, oldest first, until one fails. Batches the
+// endpoint refuses for good are removed so they do not hold back the others.
+func (w *pushWriter) replay() {
+	if w.opts.SpoolDir == "" {
+		return
+	}
+	for _, file := range w.spooled() {
+		body, err := os.ReadFile(file.path)
+		if err != nil {
+			continue
+		}
+		rejected, err := w.post(body)
+		switch {
+		case errors.IsPermanent(err):
+			w.dropped(file.entries, "rejected")
+		case err != nil:
+			return
+		case rejected > 0:
+			w.dropped(rejected, "rejected")
+		}
+		_ = os.Remove(file.path)
+	}
+}
+
+type spoolFile struct {
+	path    string
+	size    int64
+	entries int
+}
+
+// spooled lists the spool files, oldest first
+func (w *pushWriter) spooled() []spoolFile {
+	entries, err := os.ReadDir(w.opts.SpoolDir)
+	if err != nil {
+		return nil
+	}
+	files := make([]spoolFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		file := spoolFile{path: filepath.Join(w.opts.SpoolDir, entry.Name()), size: info.Size()}
+		if _, count, ok := strings.Cut(strings.TrimSuffix(entry.Name(), spoolExt), "-"); ok {
+			file.entries, _ = strconv.Atoi(count)
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files
+}
+
+func (w *pushWriter) dropped(entries int, reason string) {
+	prometheus.LogsDropped.WithLabelValues(w.app, "", reason).Add(float64(entries))
+}
+
+// Sync sends every pending entry
+func (w *pushWriter) Sync() error {
+	w.drain()
+	return nil
+}
+
+// Close sends the pending entries and stops the background goroutine
+func (w *pushWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.closing)
+	<-w.done
+	return nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encodeLoki groups the batch into streams keyed by the label fields
+func (w *pushWriter) encodeLoki(batch [][]byte) ([]byte, error) {
+	streams := make(map[string]*lokiStream)
+	var order []string
+	for _, line := range batch {
+		entry := make(map[string]interface{})
+		_ = json.Unmarshal(line, &entry)
+
+		labels := make(map[string]string, len(w.opts.Labels))
+		keys := make([]string, 0, len(w.opts.Labels))
+		for _, label := range w.opts.Labels {
+			if value, ok := entry[label]; ok {
+				labels[label] = fmt.Sprint(value)
+				keys = append(keys, label+"="+labels[label])
+			}
+		}
+		key := strings.Join(keys, ",")
+
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+		stream.Values = append(stream.Values, [2]string{entryTimestamp(entry), string(line)})
+	}
+
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{Streams: make([]*lokiStream, 0, len(order))}
+	for _, key := range order {
+		payload.Streams = append(payload.Streams, streams[key])
+	}
+	return json.Marshal(payload)
+}
+
+// entryTimestamp returns the entry time in unix nanoseconds as Loki expects
+func entryTimestamp(entry map[string]interface{}) string {
+	if value, ok := entry[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(zerolog.TimeFieldFormat, value); err == nil {
+			return strconv.FormatInt(t.UnixNano(), 10)
+		}
+	}
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// encodeBulk renders the batch as an Elasticsearch _bulk body
+func (w *pushWriter) encodeBulk(batch [][]byte) ([]byte, error) {
+	action, err := json.Marshal(map[string]map[string]string{"index": {"_index": w.opts.Index}})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range batch {
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// bulkRejected counts the items of an Elasticsearch _bulk reply which were
+// not indexed, the reply reports them with a 200 status
+func bulkRejected(reply []byte) int {
+	var bulk struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(reply, &bulk); err != nil || !bulk.Errors {
+		return 0
+	}
+	rejected := 0
+	for _, item := range bulk.Items {
+		for _, result := range item {
+			if result.Status < 200 || result.Status > 299 {
+				rejected++
+			}
+		}
+	}
+	return rejected
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kumarabd/gokit/apm/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// pushEndpoint is a local stand-in for Loki and Elasticsearch
+type pushEndpoint struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []string
+	reply    string
+	status   int32
+	requests int32
+}
+
+func newPushEndpoint(t *testing.T) *pushEndpoint {
+	e := &pushEndpoint{}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&e.requests, 1)
+		if status := atomic.LoadInt32(&e.status); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.bodies = append(e.bodies, r.Header.Get("Content-Type")+"\n"+string(body))
+		if e.reply == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = io.WriteString(w, e.reply)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *pushEndpoint) received() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.bodies...)
+}
+
+func TestLokiSink(t *testing.T) {
+	endpoint := newPushEndpoint(t)
+	log, err := New("billing", Options{Sinks: []SinkOptions{{
+		Kind: LokiSink,
+		HTTP: HTTPOptions{URL: endpoint.URL, FlushInterval: time.Hour},
+	}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.Info().Msg("first")
+	log.Error().Msg("second")
+	log.Info().Msg("third")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	bodies := endpoint.received()
+	if len(bodies) != 1 {
+		t.Fatalf("requests = %d, want 1", len(bodies))
+	}
+	parts := strings.SplitN(bodies[0], "\n", 2)
+	if parts[0] != "application/json" {
+		t.Errorf("content type = %q", parts[0])
+	}
+
+	var payload struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(parts[1]), &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if len(payload.Streams) != 2 {
+		t.Fatalf("streams = %d, want 2", len(payload.Streams))
+	}
+	info := payload.Streams[0]
+	if info.Stream["app"] != "billing" || info.Stream["level"] != "info" || len(info.Values) != 2 {
+		t.Errorf("info stream = %+v", info)
+	}
+}
+
+func TestElasticsearchSinkSpool(t *testing.T) {
+	endpoint := newPushEndpoint(t)
+	atomic.StoreInt32(&endpoint.status, http.StatusServiceUnavailable)
+
+	spool := t.TempDir()
+	w, err := newPushWriter("test", ElasticsearchSink, HTTPOptions{
+		URL:           endpoint.URL,
+		Index:         "app-logs",
+		FlushInterval: time.Hour,
+		MaxRetries:    1,
+		SpoolDir:      spool,
+	})
+	if err != nil {
+		t.Fatalf("newPushWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	_, _ = w.Write([]byte(`{"level":"info","message":"while down"}` + "\n"))
+	_ = w.Sync()
+	if files := w.spooled(); len(files) != 1 {
+		t.Fatalf("spooled batches = %d, want 1", len(files))
+	}
+
+	atomic.StoreInt32(&endpoint.status, 0)
+	_, _ = w.Write([]byte(`{"level":"info","message":"back up"}` + "\n"))
+	_ = w.Sync()
+
+	bodies := endpoint.received()
+	if len(bodies) != 2 || !strings.Contains(bodies[0], "back up") || !strings.Contains(bodies[1], "while down") {
+		t.Fatalf("received = %q", bodies)
+	}
+	want := "application/x-ndjson\n" + `{"index":{"_index":"app-logs"}}` + "\n" + `{"level":"info","message":"back up"}` + "\n"
+	if bodies[0] != want {
+		t.Errorf("bulk body = %q, want %q", bodies[0], want)
+	}
+	if entries, _ := os.ReadDir(spool); len(entries) != 0 {
+		t.Errorf("spool not emptied after replay: %d files", len(entries))
+	}
+}
+
+func TestPushWriterRetries(t *testing.T) {
+	for _, tt := range []struct {
+		retries  int
+		requests int32
+	}{
+		{-1, 1},
+		{1, 2},
+	} {
+		endpoint := newPushEndpoint(t)
+		atomic.StoreInt32(&endpoint.status, http.StatusServiceUnavailable)
+		w, err := newPushWriter("test", LokiSink, HTTPOptions{URL: endpoint.URL, FlushInterval: time.Hour, MaxRetries: tt.retries})
+		if err != nil {
+			t.Fatalf("newPushWriter() error = %v", err)
+		}
+		_, _ = w.Write([]byte(`{"level":"info","message":"lost"}` + "\n"))
+		_ = w.Sync()
+		_ = w.Close()
+		if got := atomic.LoadInt32(&endpoint.requests); got != tt.requests {
+			t.Errorf("MaxRetries %d: requests = %d, want %d", tt.retries, got, tt.requests)
+		}
+	}
+}
+
+func TestPushWriterRejected(t *testing.T) {
+	rejected := func() float64 {
+		return testutil.ToFloat64(prometheus.LogsDropped.WithLabelValues("rejected-test", "", "rejected"))
+	}
+	endpoint := newPushEndpoint(t)
+	atomic.StoreInt32(&endpoint.status, http.StatusServiceUnavailable)
+	spool := t.TempDir()
+	w, err := newPushWriter("rejected-test", LokiSink, HTTPOptions{URL: endpoint.URL, FlushInterval: time.Hour, MaxRetries: 2, SpoolDir: spool})
+	if err != nil {
+		t.Fatalf("newPushWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	_, _ = w.Write([]byte(`{"level":"info","message":"spooled"}` + "\n"))
+	_ = w.Sync()
+	if files := w.spooled(); len(files) != 1 || files[0].entries != 1 {
+		t.Fatalf("spooled batches = %+v, want one of one entry", files)
+	}
+
+	// Loki refuses entries out of order for good, neither the new batch nor
+	// the spooled one are retried or kept
+	atomic.StoreInt32(&endpoint.status, http.StatusBadRequest)
+	atomic.StoreInt32(&endpoint.requests, 0)
+	before := rejected()
+	_, _ = w.Write([]byte(`{"level":"info","message":"out of order"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"info","message":"too old"}` + "\n"))
+	_ = w.Sync()
+	if got := atomic.LoadInt32(&endpoint.requests); got != 2 {
+		t.Errorf("requests = %d, want one for the batch and one for the spooled batch", got)
+	}
+	if got := rejected() - before; got != 3 {
+		t.Errorf("rejected entries = %v, want 3", got)
+	}
+	if files := w.spooled(); len(files) != 0 {
+		t.Errorf("spooled batches = %d, want the refused batch removed", len(files))
+	}
+}
+
+func TestElasticsearchPartialFailure(t *testing.T) {
+	endpoint := newPushEndpoint(t)
+	endpoint.reply = `{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`
+	w, err := newPushWriter("partial-test", ElasticsearchSink, HTTPOptions{URL: endpoint.URL, FlushInterval: time.Hour, SpoolDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newPushWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	_, _ = w.Write([]byte(`{"level":"info","message":"indexed"}` + "\n"))
+	_, _ = w.Write([]byte(`{"level":"info","message":"unmapped"}` + "\n"))
+	_ = w.Sync()
+	if got := testutil.ToFloat64(prometheus.LogsDropped.WithLabelValues("partial-test", "", "rejected")); got != 1 {
+		t.Errorf("rejected entries = %v, want 1", got)
+	}
+	if got := atomic.LoadInt32(&endpoint.requests); got != 1 {
+		t.Errorf("requests = %d, want the accepted batch sent once", got)
+	}
+}
+
+func TestPushWriterBoundedBuffer(t *testing.T) {
+	w, err := newPushWriter("test", LokiSink, HTTPOptions{URL: "http://127.0.0.1:0", FlushInterval: time.Hour, MaxBufferBytes: 64})
+	if err != nil {
+		t.Fatalf("newPushWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 10; i++ {
+		_, _ = w.Write([]byte(`{"message":"0123456789"}`))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pendingBytes > 64 || len(w.pending) != 2 {
+		t.Errorf("pending = %d entries, %d bytes", len(w.pending), w.pendingBytes)
+	}
+}
@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// RedactTag is the struct tag marking a field as sensitive, e.g. `redact:"true"`
+const RedactTag = "redact"
+
+const redactedMask = "[REDACTED]"
+
+// sealedPrefix starts the values of tagged fields sealed by the marshaler,
+// which the handlers unseal and mask in their mode
+const sealedPrefix = redactedMask + "#"
+
+var (
+	// DefaultRedactFields are commonly sensitive field names
+	DefaultRedactFields = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "cookie"}
+
+	// DefaultRedactPatterns match card numbers and email addresses in values
+	DefaultRedactPatterns = []string{
+		`\b(?:\d[ -]?){13,16}\b`,
+		`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	}
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// tagSealer encodes the objects holding fields tagged with RedactTag
+	tagSealer = &redactor{}
+
+	// sealing encrypts the values of tagged fields with a key that never
+	// leaves the process, so that only the handlers can read them back
+	sealing     cipher.AEAD
+	sealingOnce sync.Once
+)
+
+type redactor struct {
+	fields   map[string]struct{}
+	patterns []*regexp.Regexp
+	mode     RedactMode
+	tagged   sync.Map // reflect.Type -> bool
+}
+
+// newRedactor returns a redactor which, without fields or patterns, only masks
+// the tagged fields
+func newRedactor(opts RedactOptions) (*redactor, error) {
+	r := &redactor{
+		fields: make(map[string]struct{}, len(opts.Fields)),
+		mode:   opts.Mode,
+	}
+	if r.mode == "" {
+		r.mode = FullRedactMode
+	}
+	for _, field := range opts.Fields {
+		r.fields[strings.ToLower(field)] = struct{}{}
+	}
+	for _, pattern := range opts.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, ErrInvalidRedactPattern(pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// mask renders a sensitive value according to the configured mode
+func (r *redactor) mask(value string) string {
+	switch r.mode {
+	case PartialRedactMode:
+		keep := 0
+		if len(value) > 8 {
+			keep = 4
+		}
+		return strings.Repeat("*", len(value)-keep) + value[len(value)-keep:]
+	case HashRedactMode:
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	default:
+		return redactedMask
+	}
+}
+
+// configured reports whether fields or patterns are redacted, otherwise only
+// the entries holding sealed tagged fields need processing
+func (r *redactor) configured() bool {
+	return len(r.fields) > 0 || len(r.patterns) > 0
+}
+
+// accepts reports whether an encoded entry needs processing
+func (r *redactor) accepts(p []byte) bool {
+	return r.configured() || bytes.Contains(p, []byte(sealedPrefix))
+}
+
+func (r *redactor) sensitive(key string) bool {
+	_, ok := r.fields[strings.ToLower(key)]
+	return ok
+}
+
+// process redacts a decoded entry in place
+func (r *redactor) process(entry map[string]interface{}, _ zerolog.Level) {
+	for key, value := range entry {
+		entry[key] = r.redact(key, value)
+	}
+}
+
+func (r *redactor) redact(key string, value interface{}) interface{} {
+	if s, ok := value.(string); ok && strings.HasPrefix(s, sealedPrefix) {
+		if sealed, ok := unseal(s); ok {
+			return r.mask(sealed)
+		}
+		return redactedMask
+	}
+	if r.sensitive(key) && value != nil {
+		if s, ok := value.(string); ok {
+			return r.mask(s)
+		}
+		return r.mask(fmt.Sprint(value))
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.scrub(v)
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = r.redact(k, val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = r.redact("", val)
+		}
+	}
+	return value
+}
+
+// scrub masks every pattern match inside a string value
+func (r *redactor) scrub(value string) string {
+	for _, re := range r.patterns {
+		value = re.ReplaceAllStringFunc(value, r.mask)
+	}
+	return value
+}
+
+// marshal encodes the objects logged through Interface, Fields or logr
+// key/values with their fields tagged with RedactTag sealed
+func (r *redactor) marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(r.redactValue(reflect.ValueOf(v)))
+}
+
+func (r *redactor) redactValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if !r.hasTags(v.Type()) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return r.redactValue(v.Elem())
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		r.redactStruct(v, out)
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = r.redactValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = r.redactValue(iter.Value())
+		}
+		return out
+	}
+	return v.Interface()
+}
+
+func (r *redactor) redactStruct(v reflect.Value, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		name, opts := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) > 1 {
+				opts = parts[1]
+			}
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				r.redactStruct(value, out)
+				continue
+			}
+		}
+		if field.PkgPath != "" || !value.CanInterface() {
+			continue
+		}
+		if strings.Contains(opts, "omitempty") && value.IsZero() {
+			continue
+		}
+
+		if field.Tag.Get(RedactTag) == "true" {
+			out[name] = seal(fmt.Sprint(value.Interface()))
+			continue
+		}
+		out[name] = r.redactValue(value)
+	}
+}
+
+// hasTags reports whether values of t may contain fields tagged with RedactTag
+func (r *redactor) hasTags(t reflect.Type) bool {
+	if cached, ok := r.tagged.Load(t); ok {
+		return cached.(bool)
+	}
+	found := findTags(t, make(map[reflect.Type]struct{}))
+	r.tagged.Store(t, found)
+	return found
+}
+
+func findTags(t reflect.Type, seen map[reflect.Type]struct{}) bool {
+	// Guard against recursive types
+	if _, ok := seen[t]; ok {
+		return false
+	}
+	seen[t] = struct{}{}
+
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return findTags(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Tag.Get(RedactTag) == "true" || findTags(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sealer returns the cipher sealing tagged fields, nil when the random key
+// cannot be read
+func sealer() cipher.AEAD {
+	sealingOnce.Do(func() {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return
+		}
+		sealing, _ = cipher.NewGCM(block)
+	})
+	return sealing
+}
+
+// seal encrypts the value of a tagged field behind the [REDACTED] marker
+func seal(value string) string {
+	aead := sealer()
+	if aead == nil {
+		return redactedMask
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return redactedMask
+	}
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), nil))
+}
+
+// unseal returns the value sealed by seal
+func unseal(sealed string) (string, bool) {
+	aead := sealer()
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil || aead == nil || len(data) < aead.NonceSize() {
+		return "", false
+	}
+	size := aead.NonceSize()
+	value, err := aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", false
+	}
+	return string(value), true
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/zerologr"
+	"github.com/rs/zerolog"
+)
+
+type account struct {
+	User     string `json:"user"`
+	Password string `json:"password" redact:"true"`
+}
+
+func newRedactedLogger(t *testing.T, opts RedactOptions) (zerolog.Logger, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	h, err := New("test", Options{
+		Sinks:  []SinkOptions{{Kind: WriterSink, Writer: buf, Format: JSONLogFormat}},
+		Redact: opts,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+	return h.Logger, buf
+}
+
+func decode(t *testing.T, line string) map[string]interface{} {
+	t.Helper()
+	entry := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("invalid log line %q: %v", line, err)
+	}
+	return entry
+}
+
+func TestRedactModes(t *testing.T) {
+	tests := []struct {
+		mode RedactMode
+		want string
+	}{
+		{FullRedactMode, "[REDACTED]"},
+		{PartialRedactMode, "*******6789"},
+		{HashRedactMode, "sha256:"},
+	}
+
+	for _, tt := range tests {
+		log, buf := newRedactedLogger(t, RedactOptions{Fields: []string{"token"}, Mode: tt.mode})
+		log.Info().Str("Token", "secret-6789").Str("user", "bob").
+			Interface("account", account{User: "bob", Password: "secret-6789"}).Msg("login")
+
+		entry := decode(t, buf.String())
+		if got := entry["Token"].(string); !strings.HasPrefix(got, tt.want) || strings.Contains(got, "secret") {
+			t.Errorf("mode %s: Token = %q, want prefix %q", tt.mode, got, tt.want)
+		}
+		if got := entry["account"].(map[string]interface{})["password"].(string); !strings.HasPrefix(got, tt.want) || strings.Contains(got, "secret") {
+			t.Errorf("mode %s: tagged password = %q, want prefix %q", tt.mode, got, tt.want)
+		}
+		if entry["user"] != "bob" {
+			t.Errorf("mode %s: user = %v, want bob", tt.mode, entry["user"])
+		}
+	}
+}
+
+func TestRedactPatternsAndTags(t *testing.T) {
+	log, buf := newRedactedLogger(t, RedactOptions{Patterns: DefaultRedactPatterns})
+	log.Info().Interface("account", account{User: "bob", Password: "hunter2"}).Msg("paid with 4111 1111 1111 1111 by bob@example.com")
+
+	entry := decode(t, buf.String())
+	if msg := entry["message"].(string); strings.Contains(msg, "4111") || strings.Contains(msg, "bob@example.com") {
+		t.Errorf("message not scrubbed: %q", msg)
+	}
+	acc := entry["account"].(map[string]interface{})
+	if acc["password"] != redactedMask || acc["user"] != "bob" {
+		t.Errorf("account = %v, want password redacted", acc)
+	}
+}
+
+func TestRedactLogr(t *testing.T) {
+	log, buf := newRedactedLogger(t, RedactOptions{Fields: DefaultRedactFields})
+	zerologr.New(&log).Info("request", "authorization", "Bearer abc", "path", "/")
+
+	entry := decode(t, buf.String())
+	if entry["authorization"] != redactedMask || entry["path"] != "/" {
+		t.Errorf("entry = %v, want authorization redacted", entry)
+	}
+}
+
+func TestInvalidRedactPattern(t *testing.T) {
+	if _, err := New("test", Options{Redact: RedactOptions{Patterns: []string{"("}}}); err == nil {
+		t.Error("New() with invalid pattern should fail")
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/kumarabd/gokit/apm/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// sampler applies per level burst sampling and counts the entries it drops
+type sampler struct {
+	app    string
+	levels map[zerolog.Level]zerolog.Sampler
+}
+
+// newSampler returns nil when no sampling is configured
+func newSampler(app string, opts map[Level]SamplingOptions) (*sampler, error) {
+	if len(opts) == 0 {
+		return nil, nil
+	}
+
+	s := &sampler{
+		app:    app,
+		levels: make(map[zerolog.Level]zerolog.Sampler, len(opts)),
+	}
+	for level, opt := range opts {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+
+		period := opt.Period
+		if period <= 0 {
+			period = time.Second
+		}
+		burst := &zerolog.BurstSampler{
+			Burst:  opt.First,
+			Period: period,
+		}
+		if opt.Thereafter > 0 {
+			burst.NextSampler = &zerolog.BasicSampler{N: opt.Thereafter}
+		}
+		s.levels[lvl] = burst
+	}
+	return s, nil
+}
+
+// Sample implements zerolog.Sampler
+func (s *sampler) Sample(lvl zerolog.Level) bool {
+	// Never sample out fatal and panic entries, zerolog would skip the exit
+	if lvl >= zerolog.FatalLevel {
+		return true
+	}
+	inner, ok := s.levels[lvl]
+	if !ok || inner.Sample(lvl) {
+		return true
+	}
+	prometheus.LogsDropped.WithLabelValues(s.app, lvl.String(), "sampled").Inc()
+	return false
+}
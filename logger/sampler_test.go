@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSampler(t *testing.T) {
+	s, err := newSampler("test", map[Level]SamplingOptions{
+		InfoLogLevel: {First: 2, Thereafter: 3, Period: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("newSampler() error = %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	log := zerolog.New(buf).Sample(s)
+	for i := 0; i < 8; i++ {
+		log.Info().Msg("hot")
+		log.Warn().Msg("not sampled")
+	}
+
+	// First two pass, then one in three of the remaining six
+	if got := strings.Count(buf.String(), `"hot"`); got != 4 {
+		t.Errorf("sampled info entries = %d, want 4", got)
+	}
+	if got := strings.Count(buf.String(), `"not sampled"`); got != 8 {
+		t.Errorf("warn entries = %d, want 8", got)
+	}
+}
+
+func TestSamplerInvalidLevel(t *testing.T) {
+	if _, err := newSampler("test", map[Level]SamplingOptions{"loud": {First: 1}}); err == nil {
+		t.Error("newSampler() with unknown level should fail")
+	}
+}
+
+func TestDedupWriter(t *testing.T) {
+	buf := &syncBuffer{}
+	w := newDedupWriter(zerolog.LevelWriterAdapter{Writer: buf}, "test", 50*time.Millisecond)
+	log := zerolog.New(w).With().Timestamp().Logger()
+
+	for i := 0; i < 5; i++ {
+		log.Error().Str("db", "primary").Msg("connection refused")
+	}
+	log.Error().Str("db", "replica").Msg("connection refused")
+
+	if got := strings.Count(buf.String(), "connection refused"); got != 2 {
+		t.Fatalf("entries before window end = %d, want 2", got)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	out := buf.String()
+	if !strings.Contains(out, `"repeated":4`) {
+		t.Errorf("missing repeat summary in %s", out)
+	}
+	if got := strings.Count(out, `"repeated"`); got != 1 {
+		t.Errorf("repeat summaries = %d, want 1", got)
+	}
+}
+
+func TestHandlerCloseFlushesDedup(t *testing.T) {
+	buf := &syncBuffer{}
+	log, err := New("test", Options{
+		Sinks: []SinkOptions{{Kind: WriterSink, Writer: buf}},
+		Dedup: DedupOptions{Window: time.Hour},
+		Async: AsyncOptions{BufferSize: 16},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		log.Error().Msg("connection refused")
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, `"repeated":2`) {
+		t.Errorf("missing repeat summary after Close in %s", out)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for writers flushing from timers
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// newSinks builds the writer fanning entries out to every configured sink.
+// It also returns the writers owned by the logger, which must be synced
+// and closed with it.
+func newSinks(app string, sinks []SinkOptions) (zerolog.LevelWriter, []io.Writer, error) {
+	if len(sinks) == 0 {
+		sinks = []SinkOptions{{Kind: StdoutSink, Format: ConsoleLogFormat}}
+	}
+
+	writers := make([]io.Writer, 0, len(sinks))
+	owned := make([]io.Writer, 0, len(sinks))
+	for _, opts := range sinks {
+		w, resource, err := newSink(app, opts)
+		if err != nil {
+			closeAll(owned)
+			return nil, nil, err
+		}
+		writers = append(writers, w)
+		if resource != nil {
+			owned = append(owned, resource)
+		}
+	}
+	if len(writers) == 1 {
+		return writers[0].(zerolog.LevelWriter), owned, nil
+	}
+	return zerolog.MultiLevelWriter(writers...), owned, nil
+}
+
+func newSink(app string, opts SinkOptions) (zerolog.LevelWriter, io.Writer, error) {
+	var out zerolog.LevelWriter
+	var resource io.Writer
+	switch opts.Kind {
+	case StdoutSink, "":
+		out = formatWriter(os.Stdout, opts.Format, true)
+	case StderrSink:
+		out = formatWriter(os.Stderr, opts.Format, true)
+	case FileSink:
+		w, err := newFileWriter(opts.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, resource = formatWriter(w, opts.Format, false), w
+	case WriterSink:
+		if opts.Writer == nil {
+			return nil, nil, ErrInvalidSink(opts.Kind, "writer is required")
+		}
+		out = formatWriter(opts.Writer, opts.Format, false)
+	case SyslogSink:
+		w, err := newSyslogWriter(opts.Syslog)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, resource = w, w
+	case LokiSink, ElasticsearchSink:
+		w, err := newPushWriter(app, opts.Kind, opts.HTTP)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, resource = zerolog.LevelWriterAdapter{Writer: w}, w
+	default:
+		return nil, nil, ErrInvalidSink(opts.Kind, "unknown kind")
+	}
+
+	if opts.Level != "" {
+		lvl, err := parseLevel(opts.Level)
+		if err != nil {
+			closeAll([]io.Writer{resource})
+			return nil, nil, err
+		}
+		out = &zerolog.FilteredLevelWriter{Writer: out, Level: lvl}
+	}
+	return out, resource, nil
+}
+
+// formatWriter renders JSON entries in the requested format
+func formatWriter(w io.Writer, format Format, color bool) zerolog.LevelWriter {
+	if format == ConsoleLogFormat {
+		return zerolog.LevelWriterAdapter{Writer: zerolog.ConsoleWriter{Out: w, NoColor: !color}}
+	}
+	if lw, ok := w.(zerolog.LevelWriter); ok {
+		return lw
+	}
+	return zerolog.LevelWriterAdapter{Writer: w}
+}
+
+// syncer is implemented by writers buffering entries
+type syncer interface {
+	Sync() error
+}
+
+// syncAll flushes the writers in order and returns the first error
+func syncAll(writers []io.Writer) error {
+	var first error
+	for _, w := range writers {
+		if s, ok := w.(syncer); ok {
+			if err := s.Sync(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// closeAll closes the writers in order and returns the first error
+func closeAll(writers []io.Writer) error {
+	var first error
+	for _, w := range writers {
+		if c, ok := w.(io.Closer); ok {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSinksLevelAndFormat(t *testing.T) {
+	jsonBuf, consoleBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	log, err := New("test", Options{Sinks: []SinkOptions{
+		{Kind: WriterSink, Writer: jsonBuf, Level: InfoLogLevel},
+		{Kind: WriterSink, Writer: consoleBuf, Level: ErrorLogLevel, Format: ConsoleLogFormat},
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.Debug().Msg("debug entry")
+	log.Info().Msg("info entry")
+	log.Error().Msg("error entry")
+
+	if out := jsonBuf.String(); strings.Contains(out, "debug entry") || !strings.Contains(out, `"message":"info entry"`) || !strings.Contains(out, "error entry") {
+		t.Errorf("json sink = %q", out)
+	}
+	if out := consoleBuf.String(); strings.Contains(out, "info entry") || !strings.Contains(out, "ERR error entry") {
+		t.Errorf("console sink = %q", out)
+	}
+}
+
+func TestInvalidSinks(t *testing.T) {
+	for _, sink := range []SinkOptions{
+		{Kind: "carrier-pigeon"},
+		{Kind: WriterSink},
+		{Kind: FileSink},
+		{Kind: StdoutSink, Level: "loud"},
+	} {
+		if _, err := New("test", Options{Sinks: []SinkOptions{sink}}); err == nil {
+			t.Errorf("New() with sink %+v should fail", sink)
+		}
+	}
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	w, err := newFileWriter(FileOptions{Path: path, MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("newFileWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	for file, want := range map[string]string{path: "fourth\n", path + ".1": "third\n", path + ".2": "second\n"} {
+		data, err := os.ReadFile(file)
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", file, data, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only %d backups", 2)
+	}
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// GoroutineFieldName is the field carrying the id of the logging goroutine
+const GoroutineFieldName = "goroutine"
+
+const maxStackDepth = 64
+
+// loggingPackages are skipped when capturing the stack of a logging call
+var loggingPackages = []string{
+	"runtime.",
+	"github.com/rs/zerolog",
+	"github.com/go-logr/",
+	"github.com/kumarabd/gokit/logger.",
+}
+
+// stackTracer is implemented by errors exposing the stack of their creation
+type stackTracer interface {
+	StackTrace() []runtime.Frame
+}
+
+// stackFrame is the JSON form of a frame
+type stackFrame struct {
+	Func   string `json:"func"`
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+}
+
+func toStackFrames(frames []runtime.Frame) []stackFrame {
+	out := make([]stackFrame, 0, len(frames))
+	for _, frame := range frames {
+		out = append(out, stackFrame{Func: frame.Function, Source: frame.File, Line: frame.Line})
+	}
+	return out
+}
+
+// marshalErrorStack is installed as zerolog.ErrorStackMarshaler by
+// InstallMarshalers, it returns the stack of the first error in the chain
+// that carries one
+func marshalErrorStack(err error) interface{} {
+	var tracer stackTracer
+	if !stderrors.As(err, &tracer) {
+		return nil
+	}
+	frames := tracer.StackTrace()
+	if len(frames) == 0 {
+		return nil
+	}
+	return toStackFrames(frames)
+}
+
+// stackWriter adds the stack of the logging call to error and higher level
+// entries which do not already carry the stack of their error. It must run
+// on the logging goroutine.
+type stackWriter struct {
+	next zerolog.LevelWriter
+}
+
+func (w *stackWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *stackWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.ErrorLevel || level == zerolog.NoLevel || bytes.Contains(p, []byte(`"`+zerolog.ErrorStackFieldName+`":`)) {
+		return w.next.WriteLevel(level, p)
+	}
+
+	stack, err := json.Marshal(toStackFrames(callerFrames()))
+	if err != nil {
+		return w.next.WriteLevel(level, p)
+	}
+	if _, err := w.next.WriteLevel(level, appendField(p, zerolog.ErrorStackFieldName, stack)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// callerFrames returns the current stack without the logging frames on top
+func callerFrames() []runtime.Frame {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []runtime.Frame
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		if skipping && !isLoggingFrame(frame.Function) {
+			skipping = false
+		}
+		if !skipping {
+			out = append(out, frame)
+		}
+		if !more {
+			return out
+		}
+	}
+}
+
+func isLoggingFrame(function string) bool {
+	for _, pkg := range loggingPackages {
+		if strings.HasPrefix(function, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendField adds a raw JSON field to the end of a JSON entry
+func appendField(line []byte, key string, value []byte) []byte {
+	line = bytes.TrimRight(line, " \r\n")
+	if len(line) == 0 || line[len(line)-1] != '}' {
+		return line
+	}
+	out := make([]byte, 0, len(line)+len(key)+len(value)+6)
+	out = append(out, line[:len(line)-1]...)
+	if len(line) > 2 {
+		out = append(out, ',')
+	}
+	out = strconv.AppendQuote(out, key)
+	out = append(out, ':')
+	out = append(out, value...)
+	return append(out, '}', '\n')
+}
+
+// goroutineHook adds the id of the logging goroutine to every entry
+type goroutineHook struct{}
+
+func (goroutineHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	e.Uint64(GoroutineFieldName, goroutineID())
+}
+
+// goroutineID parses the id from the "goroutine N [...]" stack header
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	header := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i > 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
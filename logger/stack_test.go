@@ -0,0 +1,60 @@
+package logger_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kumarabd/gokit/logger"
+	"github.com/kumarabd/gokit/logger/loggertest"
+)
+
+type tracedError struct{}
+
+func (tracedError) Error() string { return "traced" }
+
+func (tracedError) StackTrace() []runtime.Frame {
+	return []runtime.Frame{{Function: "origin.Func", File: "origin.go", Line: 7}}
+}
+
+func stackOf(t *testing.T, entry loggertest.Entry) []interface{} {
+	t.Helper()
+	stack, ok := entry.Fields["stack"].([]interface{})
+	if !ok || len(stack) == 0 {
+		t.Fatalf("entry %q has no stack: %v", entry.Message, entry.Fields)
+	}
+	return stack
+}
+
+func TestStackTrace(t *testing.T) {
+	rec := loggertest.NewWithOptions(t, logger.Options{Caller: true, StackTrace: true, GoroutineID: true})
+	log := rec.Handler()
+
+	log.Info().Msg("plain")
+	log.Error().Msg("site")
+	log.Error().Err(fmt.Errorf("wrapped: %w", tracedError{})).Msg("from error")
+
+	info, _ := rec.Find(logger.InfoLogLevel, "plain")
+	if _, ok := info.Fields["stack"]; ok {
+		t.Errorf("info entry should not carry a stack")
+	}
+	if caller, _ := info.Fields["caller"].(string); !strings.Contains(caller, "stack_test.go:") {
+		t.Errorf("caller = %q, want stack_test.go", caller)
+	}
+	if _, ok := info.Fields[logger.GoroutineFieldName].(float64); !ok {
+		t.Errorf("missing goroutine id in %v", info.Fields)
+	}
+
+	site, _ := rec.Find(logger.ErrorLogLevel, "site")
+	top := stackOf(t, site)[0].(map[string]interface{})
+	if !strings.HasSuffix(top["func"].(string), "logger_test.TestStackTrace") {
+		t.Errorf("top frame = %v, want the logging call", top)
+	}
+
+	fromErr, _ := rec.Find(logger.ErrorLogLevel, "from error")
+	stack := stackOf(t, fromErr)
+	if len(stack) != 1 || stack[0].(map[string]interface{})["func"] != "origin.Func" {
+		t.Errorf("stack = %v, want the error stack", stack)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logger
+
+import (
+	"log/syslog"
+
+	"github.com/rs/zerolog"
+)
+
+func newSyslogWriter(opts SyslogOptions) (zerolog.LevelWriter, error) {
+	w, err := syslog.Dial(opts.Network, opts.Address, syslog.LOG_INFO|syslog.LOG_USER, opts.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return zerolog.SyslogLevelWriter(w), nil
+}
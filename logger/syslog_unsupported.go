@@ -0,0 +1,10 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package logger
+
+import "github.com/rs/zerolog"
+
+func newSyslogWriter(opts SyslogOptions) (zerolog.LevelWriter, error) {
+	return nil, ErrInvalidSink(SyslogSink, "not supported on this platform")
+}
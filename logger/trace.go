@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/kumarabd/gokit/tracing/otel"
+	"github.com/rs/zerolog"
+)
+
+// traceHook adds the span context carried by the event context to the entry
+// and optionally records error entries as span events
+type traceHook struct {
+	spanEvents bool
+}
+
+func (h traceHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	ctx := e.GetCtx()
+	traceID, spanID, flags, ok := otel.TraceFields(ctx)
+	if !ok {
+		return
+	}
+	e.Str(otel.TraceIDKey, traceID).Str(otel.SpanIDKey, spanID).Str(otel.TraceFlagsKey, flags)
+
+	if h.spanEvents && level >= zerolog.ErrorLevel && level != zerolog.NoLevel {
+		otel.AddLogEvent(ctx, level.String(), msg)
+	}
+}
+
+// ForContext returns a handler whose entries carry the trace_id, span_id and
+// trace_flags of the span active in ctx. It shares the sinks of l.
+func (l *Handler) ForContext(ctx context.Context) *Handler {
+	return &Handler{Logger: l.With().Ctx(ctx).Logger(), writers: l.writers}
+}
@@ -0,0 +1,41 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kumarabd/gokit/logger"
+	"github.com/kumarabd/gokit/logger/loggertest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceCorrelation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+
+	rec := loggertest.NewWithOptions(t, logger.Options{SpanEvents: true})
+	log := rec.Handler()
+
+	log.ForContext(ctx).Info().Msg("handled")
+	log.Error().Ctx(ctx).Msg("failed")
+	log.Info().Msg("no span")
+	span.End()
+
+	sc := span.SpanContext()
+	rec.AssertLogged(t, logger.InfoLogLevel, "handled",
+		"trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String(), "trace_flags", "01")
+	rec.AssertLogged(t, logger.ErrorLogLevel, "failed", "trace_id", sc.TraceID().String())
+	if entry, _ := rec.Find(logger.InfoLogLevel, "no span"); entry.Fields["trace_id"] != nil {
+		t.Errorf("entry without span has trace_id %v", entry.Fields["trace_id"])
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || len(spans[0].Events()) != 1 {
+		t.Fatalf("expected one span with one event, got %d spans", len(spans))
+	}
+	if event := spans[0].Events()[0]; event.Name != "log" {
+		t.Errorf("event name = %q, want log", event.Name)
+	}
+}
@@ -1,17 +1,184 @@
 package logger
 
+import (
+	"io"
+	"time"
+)
+
 const (
 	// JSONLogFormat is json based logging format
 	JSONLogFormat = iota
 	// SyslogLogFormat is syslog based logging format
 	SyslogLogFormat
+	// ConsoleLogFormat is human readable logging format
+	ConsoleLogFormat
+)
+
+const (
+	// StdoutSink writes to the process standard output
+	StdoutSink SinkKind = "stdout"
+	// StderrSink writes to the process standard error
+	StderrSink SinkKind = "stderr"
+	// FileSink writes to a size rotated file
+	FileSink SinkKind = "file"
+	// SyslogSink writes to a local or remote syslog daemon
+	SyslogSink SinkKind = "syslog"
+	// WriterSink writes to a caller provided io.Writer
+	WriterSink SinkKind = "writer"
+	// LokiSink pushes batches to the Loki push API
+	LokiSink SinkKind = "loki"
+	// ElasticsearchSink pushes batches to the Elasticsearch _bulk API
+	ElasticsearchSink SinkKind = "elasticsearch"
+)
+
+const (
+	// FullRedactMode replaces the whole value with a fixed mask
+	FullRedactMode RedactMode = "full"
+	// PartialRedactMode masks the value but keeps its last characters visible
+	PartialRedactMode RedactMode = "partial"
+	// HashRedactMode replaces the value with a short sha256 digest
+	HashRedactMode RedactMode = "hash"
+)
+
+const (
+	// DebugLogLevel is the debug level
+	DebugLogLevel Level = "debug"
+	// InfoLogLevel is the info level
+	InfoLogLevel Level = "info"
+	// WarnLogLevel is the warn level
+	WarnLogLevel Level = "warn"
+	// ErrorLogLevel is the error level
+	ErrorLogLevel Level = "error"
+)
+
+const (
+	// BlockPolicy makes writers wait for free space in the buffer
+	BlockPolicy OverflowPolicy = "block"
+	// DropOldestPolicy discards the oldest buffered entry to make room
+	DropOldestPolicy OverflowPolicy = "drop_oldest"
+	// DropNewestPolicy discards the entry being written
+	DropNewestPolicy OverflowPolicy = "drop_newest"
 )
 
 // Format defines the logger format
 type Format int
 
+// SinkKind defines the destination of a sink
+type SinkKind string
+
+// Level defines the level of a log entry
+type Level string
+
+// OverflowPolicy defines what an asynchronous writer does when its buffer is full
+type OverflowPolicy string
+
+// RedactMode defines how a sensitive value is rendered
+type RedactMode string
+
 // Options supports different custom parameters for logger
 type Options struct {
 	Format     Format
 	DebugLevel bool
+	Redact     RedactOptions             `json:"redact,omitempty" yaml:"redact,omitempty"`
+	Sampling   map[Level]SamplingOptions `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+	Dedup      DedupOptions              `json:"dedup,omitempty" yaml:"dedup,omitempty"`
+	// Sinks receive every entry, defaults to a console sink on stdout
+	Sinks []SinkOptions `json:"sinks,omitempty" yaml:"sinks,omitempty"`
+	Async AsyncOptions  `json:"async,omitempty" yaml:"async,omitempty"`
+	// Caller adds the file:line of the logging call to every entry
+	Caller bool `json:"caller,omitempty" yaml:"caller,omitempty"`
+	// StackTrace adds a stack trace to error and higher level entries
+	StackTrace bool `json:"stack_trace,omitempty" yaml:"stack_trace,omitempty"`
+	// GoroutineID adds the id of the logging goroutine to every entry
+	GoroutineID bool `json:"goroutine_id,omitempty" yaml:"goroutine_id,omitempty"`
+	// SpanEvents records error and higher level entries as events of the active span
+	SpanEvents bool `json:"span_events,omitempty" yaml:"span_events,omitempty"`
+}
+
+// RedactOptions configures scrubbing of sensitive values before they are written
+type RedactOptions struct {
+	// Fields are the field names whose values are always redacted (case-insensitive)
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Patterns are regular expressions matched against every string value
+	Patterns []string `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+	// Mode selects how redacted values are rendered, defaults to FullRedactMode
+	Mode RedactMode `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+// SamplingOptions lets the first entries of every period through, then one in Thereafter
+type SamplingOptions struct {
+	// First is the number of entries allowed per period
+	First uint32 `json:"first,omitempty" yaml:"first,omitempty"`
+	// Thereafter keeps one in every Thereafter entries once First is exceeded, 0 drops them all
+	Thereafter uint32 `json:"thereafter,omitempty" yaml:"thereafter,omitempty"`
+	// Period is the sampling period, defaults to one second
+	Period time.Duration `json:"period,omitempty" yaml:"period,omitempty"`
+}
+
+// DedupOptions collapses identical entries seen within Window into a single line with a repeat count
+type DedupOptions struct {
+	Window time.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// AsyncOptions moves writes to the sinks off the logging goroutine
+type AsyncOptions struct {
+	// BufferSize is the number of buffered entries, 0 keeps writes synchronous
+	BufferSize int `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"`
+	// Policy applied when the buffer is full, defaults to BlockPolicy
+	Policy OverflowPolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// SinkOptions configures one destination of the logger
+type SinkOptions struct {
+	Kind SinkKind `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Level is the minimum level written to the sink, empty writes every level
+	Level Level `json:"level,omitempty" yaml:"level,omitempty"`
+	// Format of the sink, ignored by syslog and push sinks which always receive JSON
+	Format Format        `json:"format,omitempty" yaml:"format,omitempty"`
+	File   FileOptions   `json:"file,omitempty" yaml:"file,omitempty"`
+	Syslog SyslogOptions `json:"syslog,omitempty" yaml:"syslog,omitempty"`
+	HTTP   HTTPOptions   `json:"http,omitempty" yaml:"http,omitempty"`
+	Writer io.Writer     `json:"-" yaml:"-"`
+}
+
+// FileOptions configures a file sink
+type FileOptions struct {
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// MaxSize is the size in bytes after which the file is rotated, 0 disables rotation
+	MaxSize int64 `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+	// MaxBackups is the number of rotated files kept
+	MaxBackups int `json:"max_backups,omitempty" yaml:"max_backups,omitempty"`
+}
+
+// SyslogOptions configures a syslog sink, an empty Network connects to the local daemon
+type SyslogOptions struct {
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty"`
+}
+
+// HTTPOptions configures a sink pushing batches of entries over HTTP
+type HTTPOptions struct {
+	// URL is the push endpoint, e.g. http://loki:3100/loki/api/v1/push or http://es:9200/_bulk
+	URL     string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Labels are the entry fields promoted to Loki stream labels, defaults to app and level
+	Labels []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Index is the Elasticsearch index, defaults to logs
+	Index string `json:"index,omitempty" yaml:"index,omitempty"`
+	// BatchSize is the number of entries sent per request, defaults to 100
+	BatchSize int `json:"batch_size,omitempty" yaml:"batch_size,omitempty"`
+	// FlushInterval is the longest time an entry waits before being sent, defaults to one second
+	FlushInterval time.Duration `json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"`
+	// Timeout of a single request, defaults to ten seconds
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxRetries is the number of retries of a failed request, defaults to three,
+	// a negative value disables retries
+	MaxRetries int `json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	// MaxBufferBytes bounds the memory held by unsent entries, defaults to 4MiB
+	MaxBufferBytes int `json:"max_buffer_bytes,omitempty" yaml:"max_buffer_bytes,omitempty"`
+	// SpoolDir keeps batches that could not be delivered until the endpoint is back
+	SpoolDir string `json:"spool_dir,omitempty" yaml:"spool_dir,omitempty"`
+	// MaxSpoolBytes bounds the size of SpoolDir, defaults to 64MiB
+	MaxSpoolBytes int64 `json:"max_spool_bytes,omitempty" yaml:"max_spool_bytes,omitempty"`
 }
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/rs/zerolog"
+)
+
+// processor mutates a decoded log entry before it reaches the sinks
+type processor func(entry map[string]interface{}, level zerolog.Level)
+
+// processWriter decodes every JSON entry written by zerolog, runs the
+// processors over it and hands the re-encoded entry to the next writer.
+// Entries rejected by accepts are passed through untouched.
+type processWriter struct {
+	next       zerolog.LevelWriter
+	processors []processor
+	accepts    func(p []byte) bool
+}
+
+func (w *processWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *processWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if w.accepts != nil && !w.accepts(p) {
+		return w.next.WriteLevel(level, p)
+	}
+	entry := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&entry); err != nil {
+		// Not a JSON object, pass it through untouched
+		return w.next.WriteLevel(level, p)
+	}
+
+	for _, process := range w.processors {
+		process(entry, level)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(entry); err != nil {
+		return 0, err
+	}
+	if _, err := w.next.WriteLevel(level, buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
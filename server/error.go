@@ -3,7 +3,10 @@ package server
 import "github.com/kumarabd/gokit/errors"
 
 var (
-	ErrInvalidKind    = errors.New("", errors.Alert, "Unknown server kind")
-	ErrInvalidName    = errors.New("", errors.Alert, "Unknown server name")
-	ErrInvalidVersion = errors.New("", errors.Alert, "Unknown server version")
+	ErrInvalidKind = errors.Register("server.invalid_kind", errors.Alert, "Unknown server kind",
+		"The configured server kind is not one of the supported kinds (http, grpc).").New()
+	ErrInvalidName = errors.Register("server.invalid_name", errors.Alert, "Unknown server name",
+		"The server name is missing from the configuration or does not match a registered server.").New()
+	ErrInvalidVersion = errors.Register("server.invalid_version", errors.Alert, "Unknown server version",
+		"The server version is missing from the configuration or is not supported.").New()
 )
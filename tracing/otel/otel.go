@@ -1 +1,119 @@
 package otel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kumarabd/gokit/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDKey is the field name of the trace id in logs and error bodies
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the field name of the span id
+	SpanIDKey = "span_id"
+	// TraceFlagsKey is the field name of the trace flags
+	TraceFlagsKey = "trace_flags"
+
+	// LogEventName is the name of span events recorded for log entries
+	LogEventName = "log"
+
+	// ErrorCodeKey is the span attribute holding the code of an error
+	ErrorCodeKey = "error.code"
+	// ErrorSeverityKey is the span attribute holding the severity of an error
+	ErrorSeverityKey = "error.severity"
+	// ErrorMetadataPrefix prefixes the span attributes holding error metadata
+	ErrorMetadataPrefix = "error.metadata."
+)
+
+// TraceFields returns the ids and flags of the span active in ctx, ok is
+// false when ctx carries no valid span context
+func TraceFields(ctx context.Context) (traceID, spanID, flags string, ok bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", "", "", false
+	}
+	return sc.TraceID().String(), sc.SpanID().String(), sc.TraceFlags().String(), true
+}
+
+// TraceID returns the id of the trace active in ctx, or an empty string
+func TraceID(ctx context.Context) string {
+	traceID, _, _, _ := TraceFields(ctx)
+	return traceID
+}
+
+// AddLogEvent records a log entry as an event of the span active in ctx
+func AddLogEvent(ctx context.Context, level, message string, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	attrs = append([]attribute.KeyValue{
+		attribute.String("log.severity", level),
+		attribute.String("log.message", message),
+	}, attrs...)
+	span.AddEvent(LogEventName, trace.WithAttributes(attrs...))
+}
+
+// RecordError records err on the span active in ctx and marks the span as
+// failed. The code, severity and metadata of errors of the kit are set as
+// span attributes, prefixed with "error.".
+func RecordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	attrs := ErrorAttributes(err)
+	span.SetAttributes(attrs...)
+	span.RecordError(err, trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// ErrorAttributes returns the code, severity and metadata of err as
+// attributes, nil for errors outside the kit
+func ErrorAttributes(err error) []attribute.KeyValue {
+	if !errors.Is(err) {
+		return nil
+	}
+
+	metadata := errors.GetMetadata(err)
+	attrs := make([]attribute.KeyValue, 0, len(metadata)+2)
+	if code := errors.GetCode(err); code != "" {
+		attrs = append(attrs, attribute.String(ErrorCodeKey, code))
+	}
+	attrs = append(attrs, attribute.String(ErrorSeverityKey, string(errors.GetSeverity(err))))
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attrs = append(attrs, attributeOf(ErrorMetadataPrefix+key, metadata[key]))
+	}
+	return attrs
+}
+
+func attributeOf(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
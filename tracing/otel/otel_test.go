@@ -0,0 +1,44 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kumarabd/gokit/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "checkout")
+
+	err := errors.New("order.rejected", errors.Warn, "Order rejected").With("order_id", "A-1").With("retry_after", 30)
+	RecordError(ctx, err)
+	span.End()
+
+	ended := recorder.Ended()[0]
+	if ended.Status().Code != codes.Error {
+		t.Errorf("span status = %v", ended.Status())
+	}
+	want := map[attribute.Key]attribute.Value{
+		ErrorCodeKey:                        attribute.StringValue("order.rejected"),
+		ErrorSeverityKey:                    attribute.StringValue("warn"),
+		ErrorMetadataPrefix + "order_id":    attribute.StringValue("A-1"),
+		ErrorMetadataPrefix + "retry_after": attribute.IntValue(30),
+	}
+	for _, attr := range ended.Attributes() {
+		if value, ok := want[attr.Key]; ok && value == attr.Value {
+			delete(want, attr.Key)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("missing span attributes %v in %v", want, ended.Attributes())
+	}
+	if events := ended.Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("span events = %v", events)
+	}
+}